@@ -0,0 +1,236 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// The csvcols command prints selected column(s) of CSV file(s). It is similar
+// to the Unix cut command but a field is represented by a CSV column.
+//
+// Output of selected columns are in CSV using the same delimiter. The order of
+// the selected columns is the order of the output. If no file is given, it
+// reads from stdin.
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/cybrcodr/txttools/internal/fields"
+	"github.com/cybrcodr/txttools/internal/infile"
+)
+
+var (
+	fieldList  = flag.String("f", "", "list of columns to select, e.g. 1,3 or 2-5,8- or -3 for the last 3 columns")
+	nameList   = flag.String("F", "", "comma-separated list of header names of columns to select")
+	dropHeader = flag.Bool("drop-header", false, "with -F, do not print the header row")
+	delimiter  = flag.String("d", ",", "delimiter character")
+	lineTerm   = flag.String("l", "unix", "end of line terminator, windows or unix")
+)
+
+func init() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s (-f <fields> | -F <names>) [-d <delimiter>] [-l <lineterm>] [<file>...]\n\n", filepath.Base(os.Args[0]))
+		fmt.Fprintln(os.Stderr, "Prints selected columns of CSV files. If no file is given or file is '-',")
+		fmt.Fprintln(os.Stderr, "it reads from stdin. Files ending in .gz, .bz2, .zst or .xz are decompressed")
+		fmt.Fprintln(os.Stderr, "and files may be glob patterns.")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "With -F, the first row of each file is the header row and columns are")
+		fmt.Fprintln(os.Stderr, "selected by name. The header row is only printed once for all files.")
+		fmt.Fprintln(os.Stderr)
+		flag.PrintDefaults()
+	}
+}
+
+func main() {
+	flag.Parse()
+
+	comma, size := utf8.DecodeRuneInString(*delimiter)
+	if size == 0 || size != len(*delimiter) {
+		fmt.Fprintf(os.Stderr, "Invalid -d value %q, must be a single character\n", *delimiter)
+		os.Exit(1)
+	}
+
+	sel := &selector{dropHeader: *dropHeader}
+	var err error
+	switch {
+	case *fieldList != "" && *nameList != "":
+		fmt.Fprintln(os.Stderr, "Only one of -f or -F can be specified")
+		flag.Usage()
+		os.Exit(1)
+	case *nameList != "":
+		if sel.names, err = parseNames(*nameList, comma); err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid -F value: %v\n", err)
+			os.Exit(1)
+		}
+	default:
+		if sel.spec, err = fields.Parse(*fieldList); err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid -f value: %v\n", err)
+			flag.Usage()
+			os.Exit(1)
+		}
+	}
+
+	w := csv.NewWriter(os.Stdout)
+	w.Comma = comma
+	switch *lineTerm {
+	case "unix":
+	case "windows":
+		w.UseCRLF = true
+	default:
+		fmt.Fprintf(os.Stderr, "Invalid -l value %q\n", *lineTerm)
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	filenames, err := infile.Names(flag.Args())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	for _, filename := range filenames {
+		if err := processFile(filename, w, comma, sel); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+}
+
+// parseNames parses the list of column names. The list is parsed as a CSV
+// row so that names containing the delimiter can be quoted.
+func parseNames(s string, comma rune) ([]string, error) {
+	r := csv.NewReader(strings.NewReader(s))
+	r.Comma = comma
+	names, err := r.Read()
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		if name == "" {
+			return nil, errors.New("empty column name")
+		}
+	}
+	return names, nil
+}
+
+// selector selects columns of rows either by a field list or by header names.
+type selector struct {
+	spec  fields.Spec
+	names []string
+
+	dropHeader    bool
+	headerWritten bool
+}
+
+// headerIndexes returns the indexes of the selected names in the header row.
+func (s *selector) headerIndexes(header []string) ([]int, error) {
+	pos := make(map[string]int, len(header))
+	for i, name := range header {
+		if _, ok := pos[name]; !ok {
+			pos[name] = i
+		}
+	}
+	cols := make([]int, len(s.names))
+	for i, name := range s.names {
+		idx, ok := pos[name]
+		if !ok {
+			return nil, fmt.Errorf("column %q not found in header", name)
+		}
+		cols[i] = idx
+	}
+	return cols, nil
+}
+
+func processFile(filename string, w *csv.Writer, comma rune, sel *selector) error {
+	f, err := infile.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := process(f, w, comma, sel); err != nil {
+		return fmt.Errorf("%s: %v", filename, err)
+	}
+	return nil
+}
+
+func process(f io.Reader, w *csv.Writer, comma rune, sel *selector) error {
+	r := csv.NewReader(f)
+	r.Comma = comma
+	r.ReuseRecord = true
+	r.FieldsPerRecord = -1
+
+	// Rows in a file usually have the same number of columns, hence the
+	// selected indexes are only recomputed when the row size changes.
+	var cols []int
+	var out []string
+	size := -1
+	header := sel.names != nil
+	for {
+		row, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case header:
+			// Spreadsheet exports often start with a UTF-8 byte order mark,
+			// which is neither part of the first name nor printed.
+			row[0] = strings.TrimPrefix(row[0], "\ufeff")
+			if cols, err = sel.headerIndexes(row); err != nil {
+				return err
+			}
+			out = make([]string, len(cols))
+		case sel.names != nil:
+			if len(row) != size {
+				size = len(row)
+				for _, col := range cols {
+					if col >= size {
+						line, _ := r.FieldPos(0)
+						return fmt.Errorf("line %d: column %d out of range for row size %d", line, col+1, size)
+					}
+				}
+			}
+		case len(row) != size:
+			size = len(row)
+			if cols, err = sel.spec.Indexes(size); err != nil {
+				line, _ := r.FieldPos(0)
+				return fmt.Errorf("line %d: %v", line, err)
+			}
+			out = make([]string, len(cols))
+		}
+		for i, col := range cols {
+			out[i] = row[col]
+		}
+		if header {
+			header = false
+			if sel.dropHeader || sel.headerWritten {
+				continue
+			}
+			sel.headerWritten = true
+		}
+		if err := w.Write(out); err != nil {
+			return err
+		}
+		w.Flush()
+	}
+
+	return w.Error()
+}
@@ -0,0 +1,201 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cybrcodr/txttools/internal/fields"
+)
+
+func TestProcessHeaderNames(t *testing.T) {
+	for _, conf := range []struct {
+		desc  string
+		input string
+		names []string
+		want  string
+	}{
+		{
+			desc:  "plain header",
+			input: "name,year\na,2020\n",
+			names: []string{"year", "name"},
+			want:  "year,name\n2020,a\n",
+		},
+		{
+			desc:  "BOM on selected first column",
+			input: "\ufeffyear,name\n2020,a\n",
+			names: []string{"name", "year"},
+			want:  "name,year\na,2020\n",
+		},
+		{
+			desc:  "BOM on unselected first column",
+			input: "\ufeffid,name\n1,a\n",
+			names: []string{"name"},
+			want:  "name\na\n",
+		},
+		{
+			desc:  "BOM only stripped from first column",
+			input: "\ufeffid,\ufeffname\n1,a\n",
+			names: []string{"id", "\ufeffname"},
+			want:  "id,\ufeffname\n1,a\n",
+		},
+	} {
+		var buf bytes.Buffer
+		w := csv.NewWriter(&buf)
+		sel := &selector{names: conf.names}
+		if err := process(strings.NewReader(conf.input), w, ',', sel); err != nil {
+			t.Errorf("%s: process() error: %v", conf.desc, err)
+			continue
+		}
+		if got := buf.String(); got != conf.want {
+			t.Errorf("%s: process() = %q, want %q", conf.desc, got, conf.want)
+		}
+	}
+}
+
+func TestProcessFiles(t *testing.T) {
+	for _, conf := range []struct {
+		desc    string
+		fields  string
+		names   []string
+		comma   rune
+		crlf    bool
+		inputs  []string
+		want    string
+		wantErr string
+	}{
+		{
+			desc:   "fields in given order",
+			fields: "3,1",
+			inputs: []string{"a,b,c\n1,2,3\n"},
+			want:   "c,a\n3,1\n",
+		},
+		{
+			desc:   "ranges",
+			fields: "2-,1",
+			inputs: []string{"a,b,c\n1,2,3,4\n"},
+			want:   "b,c,a\n2,3,4,1\n",
+		},
+		{
+			desc:   "last columns",
+			fields: "-2",
+			inputs: []string{"a,b,c\n1,2\n"},
+			want:   "b,c\n1,2\n",
+		},
+		{
+			desc:   "quoted columns",
+			fields: "2",
+			inputs: []string{"a,\"b,\"\"c\"\"\"\n1,\"x\ny\"\n"},
+			want:   "\"b,\"\"c\"\"\"\n\"x\ny\"\n",
+		},
+		{
+			desc:   "delimiter",
+			fields: "2",
+			comma:  '#',
+			inputs: []string{"a#b,c\n1#2\n"},
+			want:   "b,c\n2\n",
+		},
+		{
+			desc:   "windows line terminator",
+			fields: "1",
+			crlf:   true,
+			inputs: []string{"a,b\r\n1,2\n"},
+			want:   "a\r\n1\r\n",
+		},
+		{
+			desc:   "multiple files",
+			fields: "2",
+			inputs: []string{"a,b\n", "c,d\n1,2\n"},
+			want:   "b\nd\n2\n",
+		},
+		{
+			desc:   "header once for multiple files",
+			names:  []string{"y"},
+			inputs: []string{"x,y\n1,2\n", "y,x\n3,4\n"},
+			want:   "y\n2\n3\n",
+		},
+		{
+			desc:    "out of range",
+			fields:  "3",
+			inputs:  []string{"a,b,c\n1,2,3\n1,2\n"},
+			want:    "c\n3\n",
+			wantErr: "file1: line 3: column 3 out of range for row size 2",
+		},
+		{
+			desc:    "out of range in second file",
+			fields:  "2-3",
+			inputs:  []string{"a,b,c\n", "1,2\n"},
+			want:    "b,c\n",
+			wantErr: "file2: line 1: column 2-3 out of range for row size 2",
+		},
+		{
+			desc:    "header name out of range",
+			names:   []string{"b"},
+			inputs:  []string{"a,b\n1,2\n3\n"},
+			want:    "b\n2\n",
+			wantErr: "file1: line 3: column 2 out of range for row size 1",
+		},
+		{
+			desc:    "missing header name",
+			names:   []string{"c"},
+			inputs:  []string{"a,b\n"},
+			wantErr: `file1: column "c" not found in header`,
+		},
+	} {
+		dir := t.TempDir()
+		sel := &selector{names: conf.names}
+		if conf.fields != "" {
+			var err error
+			if sel.spec, err = fields.Parse(conf.fields); err != nil {
+				t.Fatal(err)
+			}
+		}
+		comma := conf.comma
+		if comma == 0 {
+			comma = ','
+		}
+		var buf bytes.Buffer
+		w := csv.NewWriter(&buf)
+		w.Comma = comma
+		w.UseCRLF = conf.crlf
+
+		var err error
+		for i, input := range conf.inputs {
+			filename := filepath.Join(dir, fmt.Sprintf("file%d", i+1))
+			if err := os.WriteFile(filename, []byte(input), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err = processFile(filename, w, comma, sel); err != nil {
+				break
+			}
+		}
+		gotErr := ""
+		if err != nil {
+			gotErr = strings.TrimPrefix(err.Error(), dir+string(filepath.Separator))
+		}
+		if gotErr != conf.wantErr {
+			t.Errorf("%s: got error %q, want %q", conf.desc, gotErr, conf.wantErr)
+		}
+		if got := buf.String(); got != conf.want {
+			t.Errorf("%s: printed %q, want %q", conf.desc, got, conf.want)
+		}
+	}
+}
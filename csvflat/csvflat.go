@@ -17,52 +17,82 @@ package main
 
 import (
 	"encoding/csv"
+	"flag"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/cybrcodr/txttools/internal/fields"
+	"github.com/cybrcodr/txttools/internal/infile"
 )
 
+var fieldList = flag.String("f", "", "list of columns to output, e.g. 1,3 or 2-5,8-, default is all columns")
+
 func main() {
-	if len(os.Args) != 2 {
-		usage()
-		os.Exit(1)
-	}
+	flag.Usage = usage
+	flag.Parse()
 
-	filename := os.Args[1]
-	f := os.Stdin
-	if filename != "-" {
+	var spec fields.Spec
+	if *fieldList != "" {
 		var err error
-		f, err = os.Open(filename)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error reading file %s: %v", filename, err)
+		if spec, err = fields.Parse(*fieldList); err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid -f value: %v\n", err)
 			os.Exit(1)
 		}
-		defer f.Close()
 	}
 
-	if err := process(f); err != nil {
-		fmt.Fprintf(os.Stderr, "%v", err)
+	filenames, err := infile.Names(flag.Args())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	w := csv.NewWriter(os.Stdout)
+	for _, filename := range filenames {
+		if err := processFile(filename, w, spec); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
 	}
 }
 
 func usage() {
-	fmt.Fprintf(os.Stderr, "Usage: %s <file>\n", filepath.Base(os.Args[0]))
+	fmt.Fprintf(os.Stderr, "Usage: %s [-f <fields>] [<file>...]\n", filepath.Base(os.Args[0]))
 	fmt.Fprintln(os.Stderr)
 	fmt.Fprintln(os.Stderr, "Removes new line character in CSV columns")
-	fmt.Fprintln(os.Stderr, "If file is '-', it reads from stdin.")
+	fmt.Fprintln(os.Stderr, "If no file is given or file is '-', it reads from stdin. Files ending in")
+	fmt.Fprintln(os.Stderr, ".gz, .bz2, .zst or .xz are decompressed and files may be glob patterns.")
 	fmt.Fprintln(os.Stderr)
+	flag.PrintDefaults()
 }
 
-func process(f *os.File) error {
-	w := csv.NewWriter(os.Stdout)
+func processFile(filename string, w *csv.Writer, spec fields.Spec) error {
+	f, err := infile.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := process(f, w, spec); err != nil {
+		return fmt.Errorf("%s: %v", filename, err)
+	}
+	return nil
+}
+
+// process writes out the columns selected by spec with new lines removed. A
+// nil spec selects all columns.
+func process(f io.Reader, w *csv.Writer, spec fields.Spec) error {
 	r := csv.NewReader(f)
 	r.ReuseRecord = true
 	r.FieldsPerRecord = -1
 
+	// The selected indexes and the output record only change with the number
+	// of columns, which is usually the same for all rows of a file.
+	var idxs []int
+	var out []string
+	size := -1
 	for {
-		cols, err := r.Read()
+		row, err := r.Read()
 		if err == io.EOF {
 			break
 		}
@@ -70,11 +100,23 @@ func process(f *os.File) error {
 			return err
 		}
 
-		recs := make([]string, len(cols))
-		for i, col := range cols {
-			recs[i] = removeNewLines(col)
+		if len(row) != size {
+			size = len(row)
+			if spec == nil {
+				idxs = idxs[:0]
+				for i := 0; i < size; i++ {
+					idxs = append(idxs, i)
+				}
+			} else if idxs, err = spec.Indexes(size); err != nil {
+				line, _ := r.FieldPos(0)
+				return fmt.Errorf("line %d: %v", line, err)
+			}
+			out = make([]string, len(idxs))
+		}
+		for i, idx := range idxs {
+			out[i] = removeNewLines(row[idx])
 		}
-		if err := w.Write(recs); err != nil {
+		if err := w.Write(out); err != nil {
 			return err
 		}
 		w.Flush()
@@ -1,7 +1,10 @@
 module github.com/cybrcodr/txttools
 
-go 1.14
+go 1.24
 
 require (
 	github.com/google/go-cmp v0.2.0
+	github.com/klauspost/compress v1.13.6
+	github.com/ulikunitz/xz v0.5.12
+	golang.org/x/text v0.3.8
 )
@@ -0,0 +1,137 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package fields parses cut-style field lists used for selecting columns.
+//
+// A field list is a comma-separated list of items, where each item is one of
+//
+//	N    column N, first column is 1
+//	N-M  columns N through M
+//	N-   column N through the last column
+//	-N   the last N columns
+//
+// Items are selected in the order given and may repeat or overlap.
+package fields
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Spec is a parsed field list.
+type Spec []item
+
+// item is a single item in a field list. A zero end means the range is open
+// and ends at the last column. A zero start means the item selects the last
+// end columns.
+type item struct {
+	text       string
+	start, end int
+}
+
+// RangeError is returned by Indexes when an item selects a column that is not
+// in the row.
+type RangeError struct {
+	// Field is the item of the field list that is out of range.
+	Field string
+	// Size is the number of columns in the row.
+	Size int
+}
+
+func (e *RangeError) Error() string {
+	return fmt.Sprintf("column %s out of range for row size %d", e.Field, e.Size)
+}
+
+// Parse parses the given field list.
+func Parse(s string) (Spec, error) {
+	if s == "" {
+		return nil, errors.New("empty field list")
+	}
+	var spec Spec
+	for _, text := range strings.Split(s, ",") {
+		it, err := parseItem(text)
+		if err != nil {
+			return nil, err
+		}
+		spec = append(spec, it)
+	}
+	return spec, nil
+}
+
+func parseItem(text string) (item, error) {
+	it := item{text: text}
+	i := strings.IndexByte(text, '-')
+	if i < 0 {
+		n, err := parseNum(text)
+		if err != nil {
+			return item{}, err
+		}
+		it.start, it.end = n, n
+		return it, nil
+	}
+
+	start, end := text[:i], text[i+1:]
+	if start == "" && end == "" {
+		return item{}, fmt.Errorf("invalid field %q", text)
+	}
+	var err error
+	if start != "" {
+		if it.start, err = parseNum(start); err != nil {
+			return item{}, err
+		}
+	}
+	if end != "" {
+		if it.end, err = parseNum(end); err != nil {
+			return item{}, err
+		}
+	}
+	if it.start > 0 && it.end > 0 && it.start > it.end {
+		return item{}, fmt.Errorf("invalid decreasing range %q", text)
+	}
+	return it, nil
+}
+
+func parseNum(s string) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("invalid column %q", s)
+	}
+	return n, nil
+}
+
+// Indexes returns the 0-based indexes of the columns selected by the spec for
+// a row with the given number of columns. It returns a *RangeError if any
+// item refers to a column beyond the row.
+func (s Spec) Indexes(size int) ([]int, error) {
+	var idxs []int
+	for _, it := range s {
+		start, end := it.start, it.end
+		switch {
+		case start == 0:
+			start = size - end + 1
+			end = size
+		case end == 0:
+			end = size
+		}
+		if start < 1 || start > size || end > size {
+			return nil, &RangeError{Field: it.text, Size: size}
+		}
+		for i := start; i <= end; i++ {
+			idxs = append(idxs, i-1)
+		}
+	}
+	return idxs, nil
+}
@@ -0,0 +1,165 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fields
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseError(t *testing.T) {
+	for _, input := range []string{
+		"",
+		"0",
+		"a",
+		"1,",
+		"-",
+		"-0",
+		"3-2",
+		"1-2-3",
+		"--2",
+		"1,,2",
+	} {
+		if _, err := Parse(input); err == nil {
+			t.Errorf("input %q: got nil error", input)
+		}
+	}
+}
+
+func TestIndexes(t *testing.T) {
+	for _, conf := range []struct {
+		input string
+		size  int
+		want  []int
+	}{
+		{
+			input: "1",
+			size:  3,
+			want:  []int{0},
+		},
+		{
+			input: "3,1",
+			size:  3,
+			want:  []int{2, 0},
+		},
+		{
+			input: "2,2",
+			size:  3,
+			want:  []int{1, 1},
+		},
+		{
+			input: "2-4",
+			size:  5,
+			want:  []int{1, 2, 3},
+		},
+		{
+			input: "2-2",
+			size:  5,
+			want:  []int{1},
+		},
+		{
+			input: "3-",
+			size:  5,
+			want:  []int{2, 3, 4},
+		},
+		{
+			input: "5-",
+			size:  5,
+			want:  []int{4},
+		},
+		{
+			input: "-2",
+			size:  5,
+			want:  []int{3, 4},
+		},
+		{
+			input: "-5",
+			size:  5,
+			want:  []int{0, 1, 2, 3, 4},
+		},
+		{
+			input: "2-5,8-",
+			size:  9,
+			want:  []int{1, 2, 3, 4, 7, 8},
+		},
+		{
+			input: "-1,1-2,1",
+			size:  4,
+			want:  []int{3, 0, 1, 0},
+		},
+	} {
+		spec, err := Parse(conf.input)
+		if err != nil {
+			t.Fatalf("input %q: %v", conf.input, err)
+		}
+		got, err := spec.Indexes(conf.size)
+		if err != nil {
+			t.Errorf("input %q size %d: %v", conf.input, conf.size, err)
+			continue
+		}
+		if diff := cmp.Diff(got, conf.want); diff != "" {
+			t.Errorf("input %q size %d: diff %s", conf.input, conf.size, diff)
+		}
+	}
+}
+
+func TestIndexesRangeError(t *testing.T) {
+	for _, conf := range []struct {
+		input string
+		size  int
+		want  string
+	}{
+		{
+			input: "4",
+			size:  3,
+			want:  "4",
+		},
+		{
+			input: "1,2-4",
+			size:  3,
+			want:  "2-4",
+		},
+		{
+			input: "4-",
+			size:  3,
+			want:  "4-",
+		},
+		{
+			input: "-4",
+			size:  3,
+			want:  "-4",
+		},
+		{
+			input: "1",
+			size:  0,
+			want:  "1",
+		},
+	} {
+		spec, err := Parse(conf.input)
+		if err != nil {
+			t.Fatalf("input %q: %v", conf.input, err)
+		}
+		_, err = spec.Indexes(conf.size)
+		rerr, ok := err.(*RangeError)
+		if !ok {
+			t.Errorf("input %q size %d: got error %v, want *RangeError", conf.input, conf.size, err)
+			continue
+		}
+		if rerr.Field != conf.want || rerr.Size != conf.size {
+			t.Errorf("input %q size %d: got %+v", conf.input, conf.size, rerr)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package infile opens the inputs of the command line tools. An input is a
+// file, or stdin if its name is "-", and files with a .gz, .bz2, .zst or .xz
+// extension are transparently decompressed. Names may also be shell-style
+// glob patterns that are expanded to the files that match them.
+package infile
+
+import (
+	"compress/bzip2"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+// Stdin is the name of the input that reads from stdin.
+const Stdin = "-"
+
+// Open opens the named input for reading.
+func Open(name string) (io.ReadCloser, error) {
+	if name == Stdin {
+		return io.NopCloser(os.Stdin), nil
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	var r io.Reader
+	var closer io.Closer
+	switch filepath.Ext(name) {
+	case ".gz":
+		var zr *gzip.Reader
+		zr, err = gzip.NewReader(f)
+		r, closer = zr, zr
+	case ".bz2":
+		r = bzip2.NewReader(f)
+	case ".zst":
+		var zr *zstd.Decoder
+		if zr, err = zstd.NewReader(f); err == nil {
+			r, closer = zr, zr.IOReadCloser()
+		}
+	case ".xz":
+		r, err = xz.NewReader(f)
+	default:
+		return f, nil
+	}
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	return &decompressor{Reader: r, zr: closer, f: f}, nil
+}
+
+// decompressor reads from a decompressing reader and closes both the reader,
+// if it needs closing, and the underlying file.
+type decompressor struct {
+	io.Reader
+	zr io.Closer
+	f  *os.File
+}
+
+func (d *decompressor) Close() error {
+	if d.zr != nil {
+		d.zr.Close()
+	}
+	return d.f.Close()
+}
+
+// Expand returns the names with glob patterns replaced by the names of the
+// files that match them in sorted order. Names of existing files are kept as
+// is even if they have pattern characters, such as a file named "h[1].txt".
+// Names that are not patterns are kept as is, even if there is no such file,
+// so that opening them reports the error. A pattern that matches no files is
+// an error.
+func Expand(names []string) ([]string, error) {
+	var ret []string
+	for _, name := range names {
+		if name == Stdin || !strings.ContainsAny(name, "*?[") {
+			ret = append(ret, name)
+			continue
+		}
+		if _, err := os.Lstat(name); err == nil {
+			ret = append(ret, name)
+			continue
+		}
+		matches, err := filepath.Glob(name)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("%s: no matching files", name)
+		}
+		ret = append(ret, matches...)
+	}
+	return ret, nil
+}
+
+// Names returns the expanded names of the inputs given as arguments, or
+// stdin if there are none.
+func Names(args []string) ([]string, error) {
+	if len(args) == 0 {
+		return []string{Stdin}, nil
+	}
+	return Expand(args)
+}
@@ -0,0 +1,177 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package infile
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestOpen(t *testing.T) {
+	for _, name := range []string{
+		"lines.txt",
+		"lines.txt.gz",
+		"lines.txt.bz2",
+		"lines.txt.zst",
+		"lines.txt.xz",
+	} {
+		r, err := Open(filepath.Join("testdata", name))
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		got, err := io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if diff := cmp.Diff(string(got), "alpha\nbeta\ngamma\n"); diff != "" {
+			t.Errorf("%s: diff %s", name, diff)
+		}
+	}
+}
+
+func TestOpenErrors(t *testing.T) {
+	for _, conf := range []struct {
+		name    string
+		wantErr string
+	}{
+		{
+			name:    "missing.txt",
+			wantErr: "no such file",
+		},
+		{
+			name:    "corrupt.gz",
+			wantErr: "corrupt.gz: gzip: invalid header",
+		},
+	} {
+		_, err := Open(filepath.Join("testdata", conf.name))
+		if err == nil || !strings.Contains(err.Error(), conf.wantErr) {
+			t.Errorf("%s: got error %v, want %q", conf.name, err, conf.wantErr)
+		}
+	}
+}
+
+func TestExpand(t *testing.T) {
+	glob := func(name string) string {
+		return filepath.Join("testdata", "glob", name)
+	}
+	for _, conf := range []struct {
+		desc    string
+		names   []string
+		want    []string
+		wantErr bool
+	}{
+		{
+			desc:  "plain names",
+			names: []string{"-", glob("a.txt"), glob("missing.txt")},
+			want:  []string{"-", glob("a.txt"), glob("missing.txt")},
+		},
+		{
+			desc:  "patterns",
+			names: []string{glob("*.txt"), "-", glob("?.log")},
+			want:  []string{glob("a.txt"), glob("b.txt"), "-", glob("c.log")},
+		},
+		{
+			desc:  "character class",
+			names: []string{glob("[bc].*")},
+			want:  []string{glob("b.txt"), glob("c.log")},
+		},
+		{
+			desc:    "no match",
+			names:   []string{glob("*.csv")},
+			wantErr: true,
+		},
+		{
+			desc:    "bad pattern",
+			names:   []string{glob("[a")},
+			wantErr: true,
+		},
+	} {
+		got, err := Expand(conf.names)
+		if (err != nil) != conf.wantErr {
+			t.Errorf("%s: got error %v, want error %t", conf.desc, err, conf.wantErr)
+			continue
+		}
+		if diff := cmp.Diff(got, conf.want); diff != "" {
+			t.Errorf("%s: diff %s", conf.desc, diff)
+		}
+	}
+}
+
+// TestExpandLiteral checks that names of existing files with pattern
+// characters are not expanded.
+func TestExpandLiteral(t *testing.T) {
+	for _, conf := range []struct {
+		desc  string
+		files []string
+		name  string
+		want  []string
+	}{
+		{
+			desc:  "literal name",
+			files: []string{"h[1].txt"},
+			name:  "h[1].txt",
+			want:  []string{"h[1].txt"},
+		},
+		{
+			desc:  "literal name that is also a pattern",
+			files: []string{"h[1].txt", "h1.txt"},
+			name:  "h[1].txt",
+			want:  []string{"h[1].txt"},
+		},
+		{
+			desc:  "pattern",
+			files: []string{"h[1].txt", "h1.txt"},
+			name:  "h[12].txt",
+			want:  []string{"h1.txt"},
+		},
+	} {
+		dir := t.TempDir()
+		for _, name := range conf.files {
+			if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		got, err := Expand([]string{filepath.Join(dir, conf.name)})
+		if err != nil {
+			t.Errorf("%s: got error %v", conf.desc, err)
+			continue
+		}
+		var want []string
+		for _, name := range conf.want {
+			want = append(want, filepath.Join(dir, name))
+		}
+		if diff := cmp.Diff(got, want); diff != "" {
+			t.Errorf("%s: diff %s", conf.desc, diff)
+		}
+	}
+}
+
+func TestNames(t *testing.T) {
+	got, err := Names(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(got, []string{Stdin}); diff != "" {
+		t.Errorf("diff %s", diff)
+	}
+}
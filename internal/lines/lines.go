@@ -0,0 +1,189 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package lines reads lines of text of any length, unlike bufio.Scanner which
+// fails on lines longer than its buffer. Lines end with "\n" or "\r\n", which
+// are not part of the line, and the last line may have no line ending. Lines
+// can also be records ending with another separator, such as the NUL
+// character of find -print0.
+//
+// Errors are reported with the name of the input and the number of the line
+// being read.
+package lines
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ErrTooLong is the error of an *Error for a line that is longer than the
+// maximum length of a Reader.
+var ErrTooLong = errors.New("line too long")
+
+// Error is an error reading a line.
+type Error struct {
+	// Name is the name of the input.
+	Name string
+	// Line is the 1-based number of the line being read.
+	Line int
+	// Err is either ErrTooLong or the error of the underlying reader.
+	Err error
+	// Max is the maximum length of a line for ErrTooLong.
+	Max int
+}
+
+func (e *Error) Error() string {
+	if e.Err == ErrTooLong {
+		return fmt.Sprintf("%s:%d: %v, longer than %d bytes", e.Name, e.Line, e.Err, e.Max)
+	}
+	return fmt.Sprintf("%s:%d: %v", e.Name, e.Line, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Reader reads lines from an io.Reader.
+type Reader struct {
+	r    *bufio.Reader
+	name string
+	max  int
+	sep  []byte
+
+	buf  []byte
+	text string
+	line int
+	err  error
+}
+
+// NewReader constructs a Reader that reads lines from r, which is named name
+// in errors. Lines longer than max bytes are errors, unless max is 0.
+func NewReader(r io.Reader, name string, max int) *Reader {
+	return NewRecordReader(r, name, max, "\n")
+}
+
+// NewRecordReader is like NewReader but reads records ending with sep. Only a
+// sep of "\n" also strips a "\r" before it, and an empty sep is "\n".
+func NewRecordReader(r io.Reader, name string, max int, sep string) *Reader {
+	if sep == "" {
+		sep = "\n"
+	}
+	return &Reader{r: bufio.NewReader(r), name: name, max: max, sep: []byte(sep)}
+}
+
+// Scan advances to the next line. It returns false when there are no more
+// lines or on error.
+func (r *Reader) Scan() bool {
+	if r.err != nil {
+		return false
+	}
+	r.buf = r.buf[:0]
+	delim := r.sep[len(r.sep)-1]
+	for {
+		chunk, err := r.r.ReadSlice(delim)
+		// Allow for the line ending before checking the exact length.
+		if r.max > 0 && len(r.buf)+len(chunk) > r.max+len(r.sep)+1 {
+			return r.fail(ErrTooLong)
+		}
+		r.buf = append(r.buf, chunk...)
+		switch err {
+		case nil:
+			// The last byte of a longer separator may be in the line.
+			if !bytes.HasSuffix(r.buf, r.sep) {
+				continue
+			}
+		case bufio.ErrBufferFull:
+			continue
+		case io.EOF:
+			if len(r.buf) == 0 {
+				return false
+			}
+			// Leave the error to the next call.
+			r.err = io.EOF
+		default:
+			return r.fail(err)
+		}
+		break
+	}
+
+	b := r.buf
+	if bytes.HasSuffix(b, r.sep) {
+		b = b[:len(b)-len(r.sep)]
+		if string(r.sep) == "\n" {
+			b = bytes.TrimSuffix(b, []byte("\r"))
+		}
+	}
+	if r.max > 0 && len(b) > r.max {
+		return r.fail(ErrTooLong)
+	}
+	r.line++
+	r.text = string(b)
+	return true
+}
+
+// fail stops reading with err for the line being read.
+func (r *Reader) fail(err error) bool {
+	r.err = &Error{Name: r.name, Line: r.line + 1, Err: err, Max: r.max}
+	r.text = ""
+	return false
+}
+
+// Text returns the current line.
+func (r *Reader) Text() string {
+	return r.text
+}
+
+// Line returns the 1-based number of the current line.
+func (r *Reader) Line() int {
+	return r.line
+}
+
+// Err returns the error that stopped reading, if any. It is nil at the end
+// of the input.
+func (r *Reader) Err() error {
+	if r.err == io.EOF {
+		return nil
+	}
+	return r.err
+}
+
+// ParseSeparator parses a record separator given on the command line. It may
+// have the escape sequences of Go string literals, such as \t and \x1e, and \0
+// for the NUL character, which cannot be given as an argument.
+func ParseSeparator(s string) (string, error) {
+	var b strings.Builder
+	for t := s; t != ""; {
+		if strings.HasPrefix(t, `\0`) && (len(t) == 2 || t[2] < '0' || t[2] > '7') {
+			b.WriteByte(0)
+			t = t[2:]
+			continue
+		}
+		c, multibyte, tail, err := strconv.UnquoteChar(t, 0)
+		if err != nil {
+			return "", fmt.Errorf("invalid escape sequence in %q", s)
+		}
+		if multibyte {
+			b.WriteRune(c)
+		} else {
+			b.WriteByte(byte(c))
+		}
+		t = tail
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,223 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lines
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// errReader returns the data and then an error.
+type errReader struct {
+	data string
+	err  error
+}
+
+func (r *errReader) Read(p []byte) (int, error) {
+	if r.data == "" {
+		return 0, r.err
+	}
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
+
+func TestReader(t *testing.T) {
+	long := strings.Repeat("x", 200000)
+	errRead := errors.New("read failed")
+	for _, conf := range []struct {
+		desc    string
+		input   string
+		readErr error
+		max     int
+		sep     string
+		want    []string
+		wantErr string
+	}{
+		{
+			desc:  "empty",
+			input: "",
+		},
+		{
+			desc:  "line endings",
+			input: "a\nb\r\n\nc",
+			want:  []string{"a", "b", "", "c"},
+		},
+		{
+			desc:  "trailing newline",
+			input: "a\nb\n",
+			want:  []string{"a", "b"},
+		},
+		{
+			desc:  "carriage return without newline",
+			input: "a\rb\r",
+			want:  []string{"a\rb\r"},
+		},
+		{
+			desc:  "longer than buffer",
+			input: "a\n" + long + "\nb",
+			want:  []string{"a", long, "b"},
+		},
+		{
+			desc:  "at max",
+			input: "abc\r\nabc\nabc",
+			max:   3,
+			want:  []string{"abc", "abc", "abc"},
+		},
+		{
+			desc:    "over max",
+			input:   "abc\nabcd\nabc\n",
+			max:     3,
+			want:    []string{"abc"},
+			wantErr: "in:2: line too long, longer than 3 bytes",
+		},
+		{
+			desc:    "over max without newline",
+			input:   "abc\n" + long,
+			max:     100000,
+			want:    []string{"abc"},
+			wantErr: "in:2: line too long, longer than 100000 bytes",
+		},
+		{
+			desc:    "read error",
+			input:   "a\nb\nc",
+			readErr: errRead,
+			want:    []string{"a", "b"},
+			wantErr: "in:3: read failed",
+		},
+		{
+			desc:  "NUL separator",
+			input: "a\nb\x00c\r\n\x00\x00d",
+			sep:   "\x00",
+			want:  []string{"a\nb", "c\r\n", "", "d"},
+		},
+		{
+			desc:  "multibyte separator",
+			input: "a;;b;c;;;;d;;",
+			sep:   ";;",
+			want:  []string{"a", "b;c", "", "d"},
+		},
+		{
+			desc:  "multibyte separator longer than buffer",
+			input: long + ";" + long + ";;b",
+			sep:   ";;",
+			want:  []string{long + ";" + long, "b"},
+		},
+		{
+			desc:  "at max with separator",
+			input: "abc--abc",
+			max:   3,
+			sep:   "--",
+			want:  []string{"abc", "abc"},
+		},
+		{
+			desc:    "over max with separator",
+			input:   "abc--abcd--",
+			max:     3,
+			sep:     "--",
+			want:    []string{"abc"},
+			wantErr: "in:2: line too long, longer than 3 bytes",
+		},
+	} {
+		var input io.Reader = strings.NewReader(conf.input)
+		if conf.readErr != nil {
+			input = &errReader{data: conf.input, err: conf.readErr}
+		}
+		var r *Reader
+		if conf.sep != "" {
+			r = NewRecordReader(input, "in", conf.max, conf.sep)
+		} else {
+			r = NewReader(input, "in", conf.max)
+		}
+		var got []string
+		for r.Scan() {
+			got = append(got, r.Text())
+			if r.Line() != len(got) {
+				t.Errorf("%s: Line() = %d, want %d", conf.desc, r.Line(), len(got))
+			}
+		}
+		if diff := cmp.Diff(got, conf.want); diff != "" {
+			t.Errorf("%s: diff %s", conf.desc, diff)
+		}
+		gotErr := ""
+		if err := r.Err(); err != nil {
+			gotErr = err.Error()
+		}
+		if gotErr != conf.wantErr {
+			t.Errorf("%s: got error %q, want %q", conf.desc, gotErr, conf.wantErr)
+		}
+		if r.Scan() {
+			t.Errorf("%s: Scan() after the end returned true", conf.desc)
+		}
+	}
+}
+
+func TestErrorIs(t *testing.T) {
+	r := NewReader(strings.NewReader("abcd"), "in", 2)
+	r.Scan()
+	if !errors.Is(r.Err(), ErrTooLong) {
+		t.Errorf("got error %v, want ErrTooLong", r.Err())
+	}
+}
+
+func TestEmptySeparator(t *testing.T) {
+	r := NewRecordReader(strings.NewReader("a\r\nb"), "in", 0, "")
+	var got []string
+	for r.Scan() {
+		got = append(got, r.Text())
+	}
+	if diff := cmp.Diff(got, []string{"a", "b"}); diff != "" {
+		t.Errorf("diff %s", diff)
+	}
+}
+
+func TestParseSeparator(t *testing.T) {
+	for _, conf := range []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{input: "", want: ""},
+		{input: ",", want: ","},
+		{input: "\n", want: "\n"},
+		{input: `\n`, want: "\n"},
+		{input: `\r\n`, want: "\r\n"},
+		{input: `\t`, want: "\t"},
+		{input: `\0`, want: "\x00"},
+		{input: `\0\0`, want: "\x00\x00"},
+		{input: `\000`, want: "\x00"},
+		{input: `\x1e`, want: "\x1e"},
+		{input: `\u2028`, want: "\u2028"},
+		{input: `a"'b`, want: `a"'b`},
+		{input: "→", want: "→"},
+		{input: `\\`, want: `\`},
+		{input: `\`, wantErr: true},
+		{input: `\q`, wantErr: true},
+		{input: `\x1`, wantErr: true},
+	} {
+		got, err := ParseSeparator(conf.input)
+		if (err != nil) != conf.wantErr {
+			t.Errorf("ParseSeparator(%q) error %v, want error %v", conf.input, err, conf.wantErr)
+			continue
+		}
+		if got != conf.want {
+			t.Errorf("ParseSeparator(%q) = %q, want %q", conf.input, got, conf.want)
+		}
+	}
+}
@@ -12,49 +12,208 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-// The ljoin command joins lines in the given file with a separator into a
-// single line output.
+// The ljoin command joins lines in the given files with a separator into a
+// single line output. If no file is given, it reads from stdin.
 package main
 
 import (
 	"bufio"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/cybrcodr/txttools/internal/infile"
+	"github.com/cybrcodr/txttools/internal/lines"
 )
 
-var separator = flag.String("s", " ", "separator string between values")
+var (
+	separator = flag.String("s", " ", "separator string between values")
+	maxLine   = flag.Int("max-line", 0, "maximum length of a line in bytes, 0 for no limit")
+	nul       = flag.Bool("z", false, `lines are terminated by NUL instead of newline and the output is
+terminated by NUL, same as -rs '\0' -ors '\0'`)
+	recordSep = flag.String("rs", "\n", `separator of input lines, which may have escapes such as \t and \0
+for NUL`)
+	outputSep = flag.String("ors", "", "terminator of the output, which may have escapes")
+	quote     = flag.String("quote", "none", "quoting of values, one of "+strings.Join(quotingNames(), ", "))
+	prefix    = flag.String("prefix", "", "string before the output")
+	suffix    = flag.String("suffix", "", "string after the output, before the -ors terminator")
+	before    = flag.String("before", "", "string before each value")
+	after     = flag.String("after", "", "string after each value")
+	itemText  = flag.String("item", "", "text/template of each value with .Value and .Line, or text where {} is\nthe value")
+)
 
 func init() {
 	flag.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Usage: %s [-s <separator>] <file>\n\n", filepath.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "Usage: %s [<options>] [<file>...]\n\n", filepath.Base(os.Args[0]))
+		fmt.Fprintln(os.Stderr, "Joins lines of the files in order. If no file is given or file is '-', it")
+		fmt.Fprintln(os.Stderr, "reads from stdin. Files ending in .gz, .bz2, .zst or .xz are decompressed")
+		fmt.Fprintln(os.Stderr, "and files may be glob patterns.")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "With -quote, values are quoted so that they can be told apart even if they")
+		fmt.Fprintln(os.Stderr, "have the separator. shell quotes values as shell words, csv as CSV fields,")
+		fmt.Fprintln(os.Stderr, "json as strings of a JSON array and sql as SQL string literals. The")
+		fmt.Fprintln(os.Stderr, "separator of csv, json and sql is ',' unless -s is given.")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Each value is written as -before, the value or -item, and -after. -item is a")
+		fmt.Fprintln(os.Stderr, "text/template given the quoted value as .Value and its 1-based line number")
+		fmt.Fprintln(os.Stderr, "as .Line. Without {{, -item is text where each {} is replaced by the value.")
+		fmt.Fprintln(os.Stderr, "The output is written as -prefix, the values and -suffix.")
+		fmt.Fprintln(os.Stderr)
 		flag.PrintDefaults()
 	}
 }
 
 func main() {
 	flag.Parse()
-	if flag.NArg() != 1 {
-		flag.Usage()
+	if *maxLine < 0 {
+		fmt.Fprintf(os.Stderr, "Invalid -max-line value %d\n", *maxLine)
+		os.Exit(1)
+	}
+	if *nul {
+		*recordSep, *outputSep = `\0`, `\0`
+	}
+	rs, err := lines.ParseSeparator(*recordSep)
+	if err != nil || rs == "" {
+		fmt.Fprintf(os.Stderr, "Invalid -rs value %q\n", *recordSep)
 		os.Exit(1)
 	}
-	f, err := os.Open(flag.Arg(0))
+	ors, err := lines.ParseSeparator(*outputSep)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid -ors value %q\n", *outputSep)
+		os.Exit(1)
+	}
+	q, ok := quotings[*quote]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Invalid -quote value %q\n", *quote)
+		os.Exit(1)
+	}
+	j := &joiner{
+		rs:      rs,
+		sep:     *separator,
+		quoting: q,
+		prefix:  *prefix,
+		suffix:  *suffix,
+		before:  *before,
+		after:   *after,
+	}
+	if q.sep != "" && !isFlagSet("s") {
+		j.sep = q.sep
+	}
+	if *itemText != "" {
+		if j.item, err = parseItem(*itemText); err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid -item value: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	filenames, err := infile.Names(flag.Args())
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
-	defer f.Close()
-	scanner := bufio.NewScanner(f)
-	// Get first item.
-	if scanner.Scan() {
-		fmt.Printf("%s", scanner.Text())
+	w := bufio.NewWriter(os.Stdout)
+	err = j.join(w, filenames)
+	if err == nil {
+		_, err = io.WriteString(w, ors)
 	}
-	for scanner.Scan() {
-		fmt.Printf("%s%s", *separator, scanner.Text())
+	if ferr := w.Flush(); err == nil {
+		err = ferr
 	}
-	if err := scanner.Err(); err != nil {
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 }
+
+// isFlagSet returns whether the named flag is given.
+func isFlagSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+// joiner joins lines into a single line.
+type joiner struct {
+	// rs is the separator of input lines.
+	rs string
+	// sep is the separator between values.
+	sep string
+	// quoting quotes values and wraps the output.
+	quoting quoting
+	// prefix and suffix are written before and after the output.
+	prefix, suffix string
+	// before and after are written before and after each value.
+	before, after string
+	// item writes each value if not nil.
+	item *template.Template
+}
+
+// item is the data of the -item template.
+type item struct {
+	// Value is the quoted value.
+	Value string
+	// Line is the 1-based line number of the value over all files.
+	Line int
+}
+
+// parseItem parses the -item template. Text without actions is a shorthand
+// where each {} is replaced by the value.
+func parseItem(text string) (*template.Template, error) {
+	if !strings.Contains(text, "{{") {
+		text = strings.ReplaceAll(text, "{}", "{{.Value}}")
+	}
+	return template.New("item").Parse(text)
+}
+
+// join writes the joined lines of the named files to w.
+func (j *joiner) join(w io.Writer, filenames []string) error {
+	io.WriteString(w, j.prefix)
+	io.WriteString(w, j.quoting.open)
+	n := 0
+	for _, filename := range filenames {
+		f, err := infile.Open(filename)
+		if err != nil {
+			return err
+		}
+		r := lines.NewRecordReader(f, filename, *maxLine, j.rs)
+		for r.Scan() {
+			n++
+			if n > 1 {
+				io.WriteString(w, j.sep)
+			}
+			if err := j.writeItem(w, n, r.Text()); err != nil {
+				f.Close()
+				return fmt.Errorf("%s:%d: %v", filename, r.Line(), err)
+			}
+		}
+		f.Close()
+		if err := r.Err(); err != nil {
+			return err
+		}
+	}
+	io.WriteString(w, j.quoting.close)
+	_, err := io.WriteString(w, j.suffix)
+	return err
+}
+
+// writeItem writes the nth value given its line.
+func (j *joiner) writeItem(w io.Writer, n int, line string) error {
+	v := j.quoting.quote(line, j.sep)
+	io.WriteString(w, j.before)
+	if j.item != nil {
+		if err := j.item.Execute(w, item{Value: v, Line: n}); err != nil {
+			return err
+		}
+	} else {
+		io.WriteString(w, v)
+	}
+	_, err := io.WriteString(w, j.after)
+	return err
+}
@@ -0,0 +1,296 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestQuote(t *testing.T) {
+	for _, conf := range []struct {
+		mode  string
+		sep   string
+		value string
+		want  string
+	}{
+		{mode: "none", sep: ",", value: `a,"b" c`, want: `a,"b" c`},
+
+		{mode: "shell", sep: " ", value: "abc", want: "abc"},
+		{mode: "shell", sep: " ", value: "a/b-c.txt", want: "a/b-c.txt"},
+		{mode: "shell", sep: " ", value: "", want: "''"},
+		{mode: "shell", sep: " ", value: "a b", want: "'a b'"},
+		{mode: "shell", sep: " ", value: "it's", want: `'it'\''s'`},
+		{mode: "shell", sep: " ", value: "$HOME", want: "'$HOME'"},
+		{mode: "shell", sep: " ", value: "a\nb", want: "'a\nb'"},
+		{mode: "shell", sep: ",", value: "a,b", want: "'a,b'"},
+
+		{mode: "csv", sep: ",", value: "abc", want: "abc"},
+		{mode: "csv", sep: ",", value: "", want: ""},
+		{mode: "csv", sep: ",", value: "a,b", want: `"a,b"`},
+		{mode: "csv", sep: ";", value: "a,b", want: "a,b"},
+		{mode: "csv", sep: ";", value: "a;b", want: `"a;b"`},
+		{mode: "csv", sep: ",", value: `say "hi"`, want: `"say ""hi"""`},
+		{mode: "csv", sep: ",", value: "a\nb", want: "\"a\nb\""},
+		{mode: "csv", sep: ",", value: " a", want: `" a"`},
+
+		{mode: "json", sep: ",", value: "abc", want: `"abc"`},
+		{mode: "json", sep: ",", value: "", want: `""`},
+		{mode: "json", sep: ",", value: `a"b\c`, want: `"a\"b\\c"`},
+		{mode: "json", sep: ",", value: "a\tb\n", want: `"a\tb\n"`},
+		{mode: "json", sep: ",", value: "<&>", want: `"<&>"`},
+		{mode: "json", sep: ",", value: "\xff", want: `"�"`},
+
+		{mode: "sql", sep: ",", value: "42", want: "'42'"},
+		{mode: "sql", sep: ",", value: "", want: "''"},
+		{mode: "sql", sep: ",", value: "O'Brien", want: "'O''Brien'"},
+		{mode: "sql", sep: ",", value: "'; DROP TABLE t; --", want: "'''; DROP TABLE t; --'"},
+	} {
+		got := quotings[conf.mode].quote(conf.value, conf.sep)
+		if got != conf.want {
+			t.Errorf("%s quote(%q, %q) = %q, want %q", conf.mode, conf.value, conf.sep, got, conf.want)
+		}
+	}
+}
+
+func TestJoin(t *testing.T) {
+	dir := t.TempDir()
+	file1 := filepath.Join(dir, "file1")
+	file2 := filepath.Join(dir, "file2")
+	empty := filepath.Join(dir, "empty")
+	for name, data := range map[string]string{
+		file1: "a b\nc,d\n",
+		file2: "it's\n\"e\"",
+		empty: "",
+	} {
+		if err := os.WriteFile(name, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, conf := range []struct {
+		desc      string
+		mode      string
+		sep       string
+		filenames []string
+		want      string
+	}{
+		{
+			desc:      "none",
+			mode:      "none",
+			sep:       " ",
+			filenames: []string{file1, file2},
+			want:      `a b c,d it's "e"`,
+		},
+		{
+			desc:      "shell",
+			mode:      "shell",
+			sep:       " ",
+			filenames: []string{file1, file2},
+			want:      `'a b' c,d 'it'\''s' '"e"'`,
+		},
+		{
+			desc:      "csv",
+			mode:      "csv",
+			sep:       ",",
+			filenames: []string{file1, file2},
+			want:      `a b,"c,d",it's,"""e"""`,
+		},
+		{
+			desc:      "json",
+			mode:      "json",
+			sep:       ",",
+			filenames: []string{file1, file2},
+			want:      `["a b","c,d","it's","\"e\""]`,
+		},
+		{
+			desc:      "json without values",
+			mode:      "json",
+			sep:       ",",
+			filenames: []string{empty},
+			want:      `[]`,
+		},
+		{
+			desc:      "sql",
+			mode:      "sql",
+			sep:       ", ",
+			filenames: []string{file1, file2},
+			want:      `'a b', 'c,d', 'it''s', '"e"'`,
+		},
+	} {
+		j := &joiner{sep: conf.sep, quoting: quotings[conf.mode]}
+		var buf bytes.Buffer
+		if err := j.join(&buf, conf.filenames); err != nil {
+			t.Errorf("%s: join error %v", conf.desc, err)
+			continue
+		}
+		if got := buf.String(); got != conf.want {
+			t.Errorf("%s: got %q, want %q", conf.desc, got, conf.want)
+		}
+		if conf.mode == "json" {
+			var values []string
+			if err := json.Unmarshal(buf.Bytes(), &values); err != nil {
+				t.Errorf("%s: invalid JSON: %v", conf.desc, err)
+			}
+		}
+	}
+}
+
+func TestJoinItems(t *testing.T) {
+	dir := t.TempDir()
+	file1 := filepath.Join(dir, "file1")
+	file2 := filepath.Join(dir, "file2")
+	for name, data := range map[string]string{
+		file1: "a\nb c\n",
+		file2: "O'Brien\n",
+	} {
+		if err := os.WriteFile(name, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, conf := range []struct {
+		desc    string
+		j       joiner
+		item    string
+		want    string
+		wantErr string
+	}{
+		{
+			desc: "prefix and suffix",
+			j:    joiner{sep: ",", quoting: quotings["sql"], prefix: "IN (", suffix: ")"},
+			want: "IN ('a','b c','O''Brien')",
+		},
+		{
+			desc: "prefix and suffix around json",
+			j:    joiner{sep: ",", quoting: quotings["json"], prefix: `{"ids":`, suffix: "}"},
+			want: `{"ids":["a","b c","O'Brien"]}`,
+		},
+		{
+			desc: "before and after",
+			j:    joiner{sep: " ", quoting: quotings["none"], before: "-e ", after: ";"},
+			want: "-e a; -e b c; -e O'Brien;",
+		},
+		{
+			desc: "item shorthand",
+			j:    joiner{sep: ",", quoting: quotings["none"]},
+			item: `"{}"`,
+			want: `"a","b c","O'Brien"`,
+		},
+		{
+			desc: "item shorthand with more values",
+			j:    joiner{sep: " ", quoting: quotings["shell"]},
+			item: "{}={}",
+			want: "a=a 'b c'='b c' 'O'\\''Brien'='O'\\''Brien'",
+		},
+		{
+			desc: "item template",
+			j:    joiner{sep: "\n", quoting: quotings["none"], before: "<", after: ">"},
+			item: "{{.Line}}: {{.Value}}",
+			want: "<1: a>\n<2: b c>\n<3: O'Brien>",
+		},
+		{
+			desc: "item template of quoted values",
+			j:    joiner{sep: ", ", quoting: quotings["sql"]},
+			item: "({{.Line}}, {{.Value}})",
+			want: "(1, 'a'), (2, 'b c'), (3, 'O''Brien')",
+		},
+		{
+			desc: "item template with braces",
+			j:    joiner{sep: " ", quoting: quotings["none"]},
+			item: "{}{{.Value}}",
+			want: "{}a {}b c {}O'Brien",
+		},
+		{
+			desc:    "item template error",
+			j:       joiner{sep: " ", quoting: quotings["none"]},
+			item:    "{{.Name}}",
+			wantErr: file1 + `:1: template: item:1:2: executing "item" at <.Name>: can't evaluate field Name in type main.item`,
+		},
+	} {
+		j := conf.j
+		if conf.item != "" {
+			var err error
+			if j.item, err = parseItem(conf.item); err != nil {
+				t.Errorf("%s: parseItem error %v", conf.desc, err)
+				continue
+			}
+		}
+		var buf bytes.Buffer
+		gotErr := ""
+		if err := j.join(&buf, []string{file1, file2}); err != nil {
+			gotErr = err.Error()
+		}
+		if gotErr != conf.wantErr {
+			t.Errorf("%s: got error %q, want %q", conf.desc, gotErr, conf.wantErr)
+		}
+		if conf.wantErr != "" {
+			continue
+		}
+		if got := buf.String(); got != conf.want {
+			t.Errorf("%s: got %q, want %q", conf.desc, got, conf.want)
+		}
+	}
+}
+
+func TestParseItemError(t *testing.T) {
+	for _, text := range []string{"{{", "{{.Value", "{{end}}"} {
+		if _, err := parseItem(text); err == nil {
+			t.Errorf("parseItem(%q) returned no error", text)
+		}
+	}
+}
+
+func TestQuotingNames(t *testing.T) {
+	want := []string{"csv", "json", "none", "shell", "sql"}
+	if diff := cmp.Diff(quotingNames(), want); diff != "" {
+		t.Errorf("diff %s", diff)
+	}
+}
+
+// TestInvalidMaxLine runs main in a child process as it exits on errors.
+func TestInvalidMaxLine(t *testing.T) {
+	if args := os.Getenv("LJOIN_TEST_ARGS"); args != "" {
+		os.Args = append([]string{"ljoin"}, strings.Fields(args)...)
+		main()
+		return
+	}
+	for _, conf := range []struct {
+		args string
+		want string
+	}{
+		{args: "-max-line=-1", want: "Invalid -max-line value -1\n"},
+		{args: "-s , -max-line -20", want: "Invalid -max-line value -20\n"},
+	} {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestInvalidMaxLine$")
+		cmd.Env = append(os.Environ(), "LJOIN_TEST_ARGS="+conf.args)
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		err := cmd.Run()
+		if e, ok := err.(*exec.ExitError); !ok || e.ExitCode() != 1 {
+			t.Errorf("ljoin %s: got error %v, want exit status 1", conf.args, err)
+		}
+		if got := stderr.String(); got != conf.want {
+			t.Errorf("ljoin %s: printed %q, want %q", conf.args, got, conf.want)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// quoting is a way of quoting values so that they can be told apart in the
+// output.
+type quoting struct {
+	// quote returns the quoted value given the separator between values.
+	quote func(v, sep string) string
+	// open and close are written before and after the values.
+	open, close string
+	// sep is the separator between values if -s is not given, or empty for
+	// the default of -s.
+	sep string
+}
+
+// quotings are the quoting modes of -quote.
+var quotings = map[string]quoting{
+	"none":  {quote: func(v, sep string) string { return v }},
+	"shell": {quote: quoteShell},
+	"csv":   {quote: quoteCSV, sep: ","},
+	"json":  {quote: quoteJSON, open: "[", close: "]", sep: ","},
+	"sql":   {quote: quoteSQL, sep: ","},
+}
+
+// quotingNames returns the sorted names of the quoting modes.
+func quotingNames() []string {
+	var names []string
+	for name := range quotings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// shellSafe are the characters without special meaning in shell words.
+const shellSafe = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789_@%+=:,./-"
+
+// quoteShell quotes v as a single word for POSIX shells. Values that only
+// have characters without special meaning and do not have the separator are
+// not quoted.
+func quoteShell(v, sep string) string {
+	if v != "" && strings.Trim(v, shellSafe) == "" && (sep == "" || !strings.Contains(v, sep)) {
+		return v
+	}
+	return "'" + strings.ReplaceAll(v, "'", `'\''`) + "'"
+}
+
+// quoteCSV quotes v as a CSV field if it has the separator, quotes, line
+// breaks or leading spaces, the same as encoding/csv.
+func quoteCSV(v, sep string) string {
+	if (sep == "" || !strings.Contains(v, sep)) && !strings.ContainsAny(v, "\"\r\n") &&
+		!strings.HasPrefix(v, " ") && !strings.HasPrefix(v, "\t") {
+		return v
+	}
+	return `"` + strings.ReplaceAll(v, `"`, `""`) + `"`
+}
+
+// quoteJSON quotes v as a JSON string. Invalid UTF-8 is replaced with the
+// Unicode replacement character.
+func quoteJSON(v, sep string) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	// Encoding a string does not fail.
+	enc.Encode(v)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// quoteSQL quotes v as a standard SQL string literal, where quotes are
+// doubled. Databases that treat backslashes as escapes, such as MySQL without
+// NO_BACKSLASH_ESCAPES, are not supported.
+func quoteSQL(v, sep string) string {
+	return "'" + strings.ReplaceAll(v, "'", "''") + "'"
+}
@@ -20,40 +20,80 @@
 package main
 
 import (
-	"bufio"
+	"flag"
 	"fmt"
 	"math/rand"
 	"os"
 	"path/filepath"
 	"strconv"
-	"time"
+
+	"github.com/cybrcodr/txttools/internal/infile"
+	"github.com/cybrcodr/txttools/internal/lines"
+)
+
+var (
+	maxLine   = flag.Int("max-line", 0, "maximum length of a line in bytes, 0 for no limit")
+	nul       = flag.Bool("z", false, `line items are terminated by NUL instead of newline, same as -rs '\0' -ors '\0'`)
+	recordSep = flag.String("rs", "\n", `separator of input line items, which may have escapes such as \t and \0 for NUL`)
+	outputSep = flag.String("ors", "\n", "separator of output line items, which may have escapes")
 )
 
 func usage() {
-	fmt.Fprintf(os.Stderr, "Usage: %s <count> <file>\n", filepath.Base(os.Args[0]))
+	fmt.Fprintf(os.Stderr, "Usage: %s [-max-line <bytes>] [-z] [-rs <sep>] [-ors <sep>] <count> [<file>...]\n", filepath.Base(os.Args[0]))
 	fmt.Fprintln(os.Stderr)
-	fmt.Fprintln(os.Stderr, "Output to stdout up to <count> number of line items from given <file>.")
-	fmt.Fprintln(os.Stderr, "If file is '-', it reads from stdin.")
+	fmt.Fprintln(os.Stderr, "Output to stdout up to <count> number of line items from given files.")
+	fmt.Fprintln(os.Stderr, "If no file is given or file is '-', it reads from stdin. Files ending in")
+	fmt.Fprintln(os.Stderr, ".gz, .bz2, .zst or .xz are decompressed and files may be glob patterns.")
 	fmt.Fprintln(os.Stderr)
+	flag.PrintDefaults()
 }
 
 func main() {
-	if len(os.Args) != 3 {
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() < 1 {
 		usage()
 		os.Exit(1)
 	}
+	if *maxLine < 0 {
+		fmt.Fprintf(os.Stderr, "Invalid -max-line value %d\n", *maxLine)
+		os.Exit(1)
+	}
 
-	count, err := strconv.ParseUint(os.Args[1], 10, 64)
+	count, err := strconv.ParseUint(flag.Arg(0), 10, 64)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
 
-	items, err := readFile(os.Args[2])
+	if *nul {
+		*recordSep, *outputSep = `\0`, `\0`
+	}
+	rs, err := lines.ParseSeparator(*recordSep)
+	if err != nil || rs == "" {
+		fmt.Fprintf(os.Stderr, "Invalid -rs value %q\n", *recordSep)
+		os.Exit(1)
+	}
+	ors, err := lines.ParseSeparator(*outputSep)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid -ors value %q\n", *outputSep)
+		os.Exit(1)
+	}
+
+	filenames, err := infile.Names(flag.Args()[1:])
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
+	var items []string
+	for _, filename := range filenames {
+		fileItems, err := readFile(filename, rs)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		items = append(items, fileItems...)
+	}
 
 	size := len(items)
 	if count >= uint64(size) {
@@ -61,32 +101,27 @@ func main() {
 		os.Exit(1)
 	}
 
-	rand.Seed(time.Now().UnixNano())
-
 	for i := uint64(0); i < count; i++ {
 		idx := rand.Intn(size)
-		fmt.Println(items[idx])
+		fmt.Print(items[idx], ors)
 		items[idx] = items[size-1]
 		size--
 	}
 }
 
-func readFile(filename string) ([]string, error) {
-	f := os.Stdin
-	if filename != "-" {
-		var err error
-		if f, err = os.Open(filename); err != nil {
-			return nil, err
-		}
-		defer f.Close()
+func readFile(filename, sep string) ([]string, error) {
+	f, err := infile.Open(filename)
+	if err != nil {
+		return nil, err
 	}
-	scanner := bufio.NewScanner(f)
+	defer f.Close()
+	r := lines.NewRecordReader(f, filename, *maxLine, sep)
 
 	items := []string{}
-	for scanner.Scan() {
-		items = append(items, scanner.Text())
+	for r.Scan() {
+		items = append(items, r.Text())
 	}
-	if err := scanner.Err(); err != nil {
+	if err := r.Err(); err != nil {
 		return nil, err
 	}
 	return items, nil
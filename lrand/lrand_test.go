@@ -0,0 +1,51 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// TestInvalidMaxLine runs main in a child process as it exits on errors.
+func TestInvalidMaxLine(t *testing.T) {
+	if args := os.Getenv("LRAND_TEST_ARGS"); args != "" {
+		os.Args = append([]string{"lrand"}, strings.Fields(args)...)
+		main()
+		return
+	}
+	for _, conf := range []struct {
+		args string
+		want string
+	}{
+		{args: "-max-line=-1 3", want: "Invalid -max-line value -1\n"},
+		{args: "-z -max-line -20 3 -", want: "Invalid -max-line value -20\n"},
+	} {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestInvalidMaxLine$")
+		cmd.Env = append(os.Environ(), "LRAND_TEST_ARGS="+conf.args)
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		err := cmd.Run()
+		if e, ok := err.(*exec.ExitError); !ok || e.ExitCode() != 1 {
+			t.Errorf("lrand %s: got error %v, want exit status 1", conf.args, err)
+		}
+		if got := stderr.String(); got != conf.want {
+			t.Errorf("lrand %s: printed %q, want %q", conf.args, got, conf.want)
+		}
+	}
+}
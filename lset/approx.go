@@ -0,0 +1,116 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"github.com/cybrcodr/txttools/internal/infile"
+	"github.com/cybrcodr/txttools/internal/lines"
+	"github.com/cybrcodr/txttools/lset/internal/approx"
+)
+
+// approxCommands are the commands that can be done approximately.
+var approxCommands = map[string]bool{
+	"cross": true,
+	"minus": true,
+	"stats": true,
+}
+
+// approxCommand runs the named command approximately.
+//
+// For minus and cross, a Bloom filter is built from each file but the first
+// and lines of the first file are printed in the order they are read if their
+// keys are in none or all of the filters. A line of the first file is wrongly
+// taken to be in another file with a probability of at most rate, hence minus
+// may miss lines and cross may have extra lines. Lines are not deduplicated.
+// The filters take at least -1.44*log2(rate) bits per distinct key, which is
+// far less than the keys but still grows with the files.
+//
+// For stats, the number of distinct keys of each file and of their union are
+// estimated with HyperLogLog in memory that does not depend on the sizes of
+// the files, from which the other statistics are derived.
+func approxCommand(p printer, name string, filenames []string, opts readOptions, rate float64) error {
+	if name == "stats" {
+		return approxStats(p, filenames, opts)
+	}
+
+	filters := make([]*approx.Bloom, len(filenames)-1)
+	for i, filename := range filenames[1:] {
+		b := approx.NewBloom(rate)
+		err := scanKeys(filename, opts, func(key, line string) {
+			b.Add(key)
+		})
+		if err != nil {
+			return err
+		}
+		filters[i] = b
+	}
+
+	in := make([]bool, len(filenames))
+	return scanKeys(filenames[0], opts, func(key, line string) {
+		in[0] = true
+		n := 0
+		for i, b := range filters {
+			if in[i+1] = b.Contains(key); in[i+1] {
+				n++
+			}
+		}
+		if (name == "minus" && n == 0) || (name == "cross" && n == len(filters)) {
+			p.print(result{line: line, in: append([]bool(nil), in...)})
+		}
+	})
+}
+
+// approxStats prints the estimated statistics of the first 2 files.
+func approxStats(p printer, filenames []string, opts readOptions) error {
+	var hlls [2]*approx.HyperLogLog
+	for i, filename := range filenames {
+		h := approx.NewHyperLogLog()
+		err := scanKeys(filename, opts, func(key, line string) {
+			h.Add(key)
+		})
+		if err != nil {
+			return err
+		}
+		hlls[i] = h
+	}
+	size1, size2 := hlls[0].Estimate(), hlls[1].Estimate()
+	union := *hlls[0]
+	union.Merge(hlls[1])
+	// The estimates are independent, the intersection is clamped to what
+	// the sizes allow.
+	cross := min(max(size1+size2-union.Estimate(), 0), size1, size2)
+	p.printStats(newSetStats(size1, size2, cross))
+	return nil
+}
+
+// scanKeys calls fn with the key of each line item of the named file.
+func scanKeys(filename string, opts readOptions, fn func(key, line string)) error {
+	f, err := infile.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r := lines.NewRecordReader(f, filename, opts.maxLine, opts.sep)
+	for r.Scan() {
+		line := r.Text()
+		key := line
+		if opts.key != nil {
+			key = opts.key(line)
+		}
+		fn(key, line)
+	}
+	return r.Err()
+}
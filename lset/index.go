@@ -0,0 +1,162 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/cybrcodr/txttools/lset/internal/index"
+)
+
+// buildIndex writes an index of the line items of the named file to out, or to
+// stdout if out is "-". The file is sorted in chunks of up to limit bytes
+// written out to temporary files in dir like with -spill.
+func buildIndex(filename, out string, opts readOptions, options, dir string, limit int) (err error) {
+	s, err := spillFile(filename, opts, dir, limit)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	f := os.Stdout
+	if out != "-" {
+		if f, err = os.Create(out); err != nil {
+			return err
+		}
+		defer func() {
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+			if err != nil {
+				os.Remove(out)
+			}
+		}()
+	}
+	w := index.NewWriter(f, options)
+	for s.Next() {
+		if err := w.Add(s.Key(), s.Line()); err != nil {
+			return fmt.Errorf("%s: %v", out, err)
+		}
+	}
+	if err := s.Err(); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("%s: %v", out, err)
+	}
+	return nil
+}
+
+// openIndexes opens the files that are indexes, leaving nil for other files.
+// Indexes must have been built with the given key options.
+func openIndexes(filenames []string, options string) ([]*index.Index, error) {
+	indexes := make([]*index.Index, len(filenames))
+	for i, filename := range filenames {
+		if filename == "-" {
+			continue
+		}
+		x, err := index.Open(filename)
+		if err == index.ErrNotIndex {
+			continue
+		}
+		if err != nil {
+			closeIndexes(indexes)
+			return nil, err
+		}
+		indexes[i] = x
+		if x.Options() != options {
+			closeIndexes(indexes)
+			return nil, fmt.Errorf("%s: index was built with key options %s, not %s", filename, x.Options(), options)
+		}
+	}
+	return indexes, nil
+}
+
+func closeIndexes(indexes []*index.Index) {
+	for _, x := range indexes {
+		if x != nil {
+			x.Close()
+		}
+	}
+}
+
+// indexStream is a stream over the keys of an index.
+type indexStream struct {
+	x  *index.Index
+	it *index.Iterator
+}
+
+func (s *indexStream) Next() bool {
+	return s.it.Next()
+}
+
+func (s *indexStream) Key() string {
+	return s.it.Key()
+}
+
+func (s *indexStream) Line() string {
+	return s.it.Line()
+}
+
+func (s *indexStream) Err() error {
+	if err := s.it.Err(); err != nil {
+		return fmt.Errorf("%s: %v", s.x.Name(), err)
+	}
+	return nil
+}
+
+// Close does nothing as the index is closed separately.
+func (s *indexStream) Close() error {
+	return nil
+}
+
+// canLookup returns whether the named command can be run by looking up the
+// keys of the first file in the other files, which are all indexes.
+func canLookup(name string, indexes []*index.Index) bool {
+	if (name != "minus" && name != "cross") || indexes[0] != nil {
+		return false
+	}
+	for _, x := range indexes[1:] {
+		if x == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// lookupCommand runs minus or cross by looking up each key of the first
+// stream in the indexes of the other files. Only the blocks of the indexes
+// that may hold the keys are read.
+func lookupCommand(p printer, name string, first stream, indexes []*index.Index) error {
+	in := make([]bool, len(indexes))
+	for first.Next() {
+		in[0] = true
+		n := 0
+		for i, x := range indexes[1:] {
+			_, ok, err := x.Lookup(first.Key())
+			if err != nil {
+				return fmt.Errorf("%s: %v", x.Name(), err)
+			}
+			if in[i+1] = ok; ok {
+				n++
+			}
+		}
+		if (name == "minus" && n == 0) || (name == "cross" && n == len(indexes)-1) {
+			p.print(result{line: first.Line(), in: append([]bool(nil), in...)})
+		}
+	}
+	return first.Err()
+}
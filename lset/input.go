@@ -0,0 +1,211 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"runtime"
+	"sync"
+
+	"github.com/cybrcodr/txttools/internal/infile"
+	"github.com/cybrcodr/txttools/internal/lines"
+	"github.com/cybrcodr/txttools/lset/internal/set"
+)
+
+// input is the set of line items of a file. Set operations are done on the
+// keys of the line items while the line items themselves are printed.
+type input struct {
+	keys set.Strings
+	// lines maps each key to the first line item with that key. It is nil if
+	// line items are their own keys.
+	lines map[string]string
+	// order lists the keys in the order first seen. It is only tracked when
+	// output follows the order of the files.
+	order []string
+	// counts is the number of line items of each key. It is only tracked for
+	// multiset operations.
+	counts set.Counts
+}
+
+// readOptions controls how line items are read into an input.
+type readOptions struct {
+	// key returns the key of a line item, nil if line items are their own
+	// keys.
+	key keyFunc
+	// trackOrder keeps the order of the keys.
+	trackOrder bool
+	// bag keeps the number of line items of each key.
+	bag bool
+	// maxLine is the maximum length of a line item, 0 for no limit.
+	maxLine int
+	// sep is the separator of line items, "\n" if empty.
+	sep string
+}
+
+// line returns the line item for the given key.
+func (in *input) line(key string) string {
+	if in.lines == nil {
+		return key
+	}
+	return in.lines[key]
+}
+
+// lineOf returns the line item for the given key from the first input that
+// contains the key.
+func lineOf(inputs []*input, key string) string {
+	for _, in := range inputs {
+		if in.keys.Contains(key) {
+			return in.line(key)
+		}
+	}
+	return key
+}
+
+// readFiles reads the line items of the named files concurrently.
+func readFiles(filenames []string, opts readOptions) ([]*input, error) {
+	inputs := make([]*input, len(filenames))
+	errs := make([]error, len(filenames))
+	var wg sync.WaitGroup
+	for i, filename := range filenames {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			inputs[i], errs[i] = readFile(filename, opts)
+		}()
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return inputs, nil
+}
+
+// chunkLines is the number of line items in a chunk.
+const chunkLines = 4096
+
+// chunk is consecutive line items of a file with their keys.
+type chunk struct {
+	seq   int
+	lines []string
+	keys  []string
+}
+
+// readFile reads the line items of the named file. Chunks of line items are
+// keyed and added to a concurrent set of keys by concurrent workers, then the
+// line items, order and counts of the keys are added in file order. The keys
+// are taken out of the concurrent set at the end without copying them.
+func readFile(filename string, opts readOptions) (*input, error) {
+	f, err := infile.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	workers := runtime.GOMAXPROCS(0)
+	keys := set.NewShardedStrings()
+	chunks := make(chan *chunk)
+	done := make(chan *chunk)
+	// inFlight holds a token for each chunk that is read but not yet added,
+	// which bounds the chunks that are held until earlier chunks are done.
+	inFlight := make(chan struct{}, 2*workers)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for c := range chunks {
+				c.keys = c.lines
+				if opts.key != nil {
+					c.keys = make([]string, len(c.lines))
+					for j, line := range c.lines {
+						c.keys[j] = opts.key(line)
+					}
+				}
+				keys.Add(c.keys...)
+				done <- c
+			}
+		}()
+	}
+
+	// The error of the reader is read once done is closed, which happens
+	// after the reader stops.
+	var readErr error
+	go func() {
+		defer close(chunks)
+		r := lines.NewRecordReader(f, filename, opts.maxLine, opts.sep)
+		c := &chunk{}
+		for r.Scan() {
+			c.lines = append(c.lines, r.Text())
+			if len(c.lines) == chunkLines {
+				inFlight <- struct{}{}
+				chunks <- c
+				c = &chunk{seq: c.seq + 1}
+			}
+		}
+		if len(c.lines) > 0 {
+			inFlight <- struct{}{}
+			chunks <- c
+		}
+		readErr = r.Err()
+	}()
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	in := &input{}
+	if opts.key != nil {
+		in.lines = map[string]string{}
+	}
+	if opts.bag {
+		in.counts = set.Counts{}
+	}
+	var seen set.Strings
+	if opts.trackOrder {
+		seen = set.Strings{}
+	}
+	// Chunks are done out of order, hence are held until the chunks before
+	// them are done.
+	pending := map[int]*chunk{}
+	next := 0
+	for c := range done {
+		pending[c.seq] = c
+		for c := pending[next]; c != nil; c = pending[next] {
+			delete(pending, next)
+			next++
+			<-inFlight
+			for j, k := range c.keys {
+				if opts.bag {
+					in.counts.Add(k)
+				}
+				if opts.key != nil {
+					if _, ok := in.lines[k]; !ok {
+						in.lines[k] = c.lines[j]
+					}
+				}
+				if opts.trackOrder && !seen.Contains(k) {
+					seen.Add(k)
+					in.order = append(in.order, k)
+				}
+			}
+		}
+	}
+	if readErr != nil {
+		return nil, readErr
+	}
+	in.keys = keys.Take()
+	return in, nil
+}
@@ -0,0 +1,40 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package approx contains probabilistic data structures for strings that use
+// far less memory than the strings: a Bloom filter for set membership, which
+// takes a few bits per string, and HyperLogLog for counting distinct strings
+// in a fixed amount of memory.
+package approx
+
+import "hash/fnv"
+
+// hash returns a 64-bit hash of s. FNV-1a is fast but its high bits are poorly
+// mixed for similar strings, hence it is finished with the murmur3 mixer.
+func hash(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	return mix(h.Sum64())
+}
+
+// mix is the finalizer of murmur3, which makes every bit of x affect every bit
+// of the result.
+func mix(x uint64) uint64 {
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
@@ -0,0 +1,171 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package approx
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/cybrcodr/txttools/lset/internal/set"
+)
+
+func TestBloom(t *testing.T) {
+	for _, conf := range []struct {
+		n    int
+		rate float64
+	}{
+		{n: 0, rate: 0.01},
+		{n: 1000, rate: 0.01},
+		{n: 100000, rate: 0.01},
+		// Grows past the first filter.
+		{n: 300000, rate: 0.001},
+	} {
+		b := NewBloom(conf.rate)
+		added := set.Strings{}
+		for i := 0; i < conf.n; i++ {
+			s := fmt.Sprintf("line %d", i)
+			b.Add(s)
+			added.Add(s)
+		}
+		for s := range added {
+			if !b.Contains(s) {
+				t.Fatalf("n=%d: Contains(%q) = false for added string", conf.n, s)
+			}
+		}
+
+		const trials = 200000
+		fp := 0
+		for i := 0; i < trials; i++ {
+			s := fmt.Sprintf("other %d", i)
+			if added.Contains(s) {
+				continue
+			}
+			if b.Contains(s) {
+				fp++
+			}
+		}
+		if got := float64(fp) / trials; got > conf.rate {
+			t.Errorf("n=%d rate=%v: false positive rate %v", conf.n, conf.rate, got)
+		}
+	}
+}
+
+// TestBloomLargePositions checks that the bit positions cover filters larger
+// than 2^32 bits uniformly, which are too large to fill in a test, and that
+// the false positive rate measured from those positions is the expected one.
+func TestBloomLargePositions(t *testing.T) {
+	for _, m := range []uint64{1<<32 + 12345, 1<<40 + 3, 10 * (1 << 40)} {
+		const (
+			buckets = 64
+			n       = 100000
+			k       = 7
+		)
+		f := &filter{m: m, k: k}
+		var counts [buckets]int
+		for j := 0; j < n; j++ {
+			h1, h2 := hashes(hash(fmt.Sprintf("line %d", j)))
+			for i := uint64(0); i < k; i++ {
+				bit := f.position(h1, h2, i)
+				if bit >= m {
+					t.Fatalf("m=%d: position %d out of range", m, bit)
+				}
+				counts[bit/(m/buckets+1)]++
+			}
+		}
+		want := float64(n*k) / buckets
+		for b, c := range counts {
+			if math.Abs(float64(c)-want) > 0.05*want {
+				t.Errorf("m=%d: bucket %d has %d positions, want about %.0f", m, b, c, want)
+			}
+		}
+
+		// Once the filter holds n' strings, each bit is set with probability
+		// p = 1-exp(-k*n'/m). Marking a random fraction p of the buckets as
+		// set, a string that was not added is found with probability p^k if
+		// its positions are independent, which is the rate of the filter.
+		const p = 0.5
+		rnd := rand.New(rand.NewSource(int64(m)))
+		var marked [1 << 16]bool
+		for i := range marked {
+			marked[i] = rnd.Float64() < p
+		}
+		fp := 0
+		const trials = 200000
+		for j := 0; j < trials; j++ {
+			h1, h2 := hashes(hash(fmt.Sprintf("other %d", j)))
+			found := true
+			for i := uint64(0); i < k && found; i++ {
+				found = marked[f.position(h1, h2, i)/(m/uint64(len(marked))+1)]
+			}
+			if found {
+				fp++
+			}
+		}
+		want = math.Pow(p, k)
+		if got := float64(fp) / trials; math.Abs(got-want) > 0.2*want {
+			t.Errorf("m=%d: false positive rate %v, want about %v", m, got, want)
+		}
+	}
+}
+
+func TestHyperLogLog(t *testing.T) {
+	for _, n := range []int{0, 1, 100, 10000, 40000, 50000, 100000, 1000000} {
+		h := NewHyperLogLog()
+		exact := set.Strings{}
+		// Add every string twice as duplicates must not be counted.
+		for r := 0; r < 2; r++ {
+			for i := 0; i < n; i++ {
+				s := fmt.Sprintf("line %d", i)
+				h.Add(s)
+				exact.Add(s)
+			}
+		}
+		got, want := h.Estimate(), len(exact)
+		if err := relErr(got, want); err > 0.03 {
+			t.Errorf("n=%d: Estimate() = %d, want %d within 3%%", n, got, want)
+		}
+	}
+}
+
+func TestHyperLogLogMerge(t *testing.T) {
+	a, b := NewHyperLogLog(), NewHyperLogLog()
+	union := set.Strings{}
+	for i := 0; i < 60000; i++ {
+		s := fmt.Sprintf("line %d", i)
+		a.Add(s)
+		union.Add(s)
+	}
+	for i := 30000; i < 100000; i++ {
+		s := fmt.Sprintf("line %d", i)
+		b.Add(s)
+		union.Add(s)
+	}
+	a.Merge(b)
+	got, want := a.Estimate(), len(union)
+	if err := relErr(got, want); err > 0.03 {
+		t.Errorf("Estimate() of merged = %d, want %d within 3%%", got, want)
+	}
+}
+
+// relErr returns the error of got relative to want, or the absolute error if
+// want is 0.
+func relErr(got, want int) float64 {
+	if want == 0 {
+		return float64(got)
+	}
+	return math.Abs(float64(got-want)) / float64(want)
+}
@@ -0,0 +1,137 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package approx
+
+import (
+	"math"
+	"math/bits"
+)
+
+// Bloom is a Bloom filter, a set that may report that it contains a string
+// that was never added with a bounded false positive rate, but never reports
+// that it does not contain a string that was added.
+//
+// The filter grows as strings are added so that the number of strings need
+// not be known in advance. It is made of a series of filters where each one
+// is twice as large as the previous one and has half of its false positive
+// rate, which keeps the overall rate under the given rate. A filter takes
+// -1.44*log2(rate) bits per string for its own rate, hence the filter as a
+// whole takes a few more bits per string as it grows, and up to twice that
+// until the last filter is full.
+type Bloom struct {
+	rate    float64
+	filters []*filter
+}
+
+// initialCapacity is the number of strings that the first filter holds.
+const initialCapacity = 1 << 16
+
+// NewBloom constructs a Bloom filter with a false positive rate of at most
+// rate, which must be between 0 and 1.
+func NewBloom(rate float64) *Bloom {
+	return &Bloom{rate: rate}
+}
+
+// Add adds s to the filter.
+func (b *Bloom) Add(s string) {
+	h := hash(s)
+	if len(b.filters) > 0 && b.filters[len(b.filters)-1].contains(h) {
+		return
+	}
+	if len(b.filters) == 0 || b.filters[len(b.filters)-1].full() {
+		n := len(b.filters)
+		// The rates of the filters are rate/2, rate/4, ... which add up to
+		// less than rate.
+		b.filters = append(b.filters, newFilter(initialCapacity<<n, b.rate/float64(uint64(2)<<n)))
+	}
+	b.filters[len(b.filters)-1].add(h)
+}
+
+// Contains returns false if s was not added to the filter, and true if it
+// was or, with a probability of at most the false positive rate, if it was
+// not.
+func (b *Bloom) Contains(s string) bool {
+	h := hash(s)
+	for _, f := range b.filters {
+		if f.contains(h) {
+			return true
+		}
+	}
+	return false
+}
+
+// filter is a fixed size Bloom filter.
+type filter struct {
+	bits     []uint64
+	m        uint64
+	k        uint64
+	n        int
+	capacity int
+}
+
+// newFilter constructs a filter that holds capacity strings with the given
+// false positive rate using the optimal number of bits and hash functions.
+func newFilter(capacity int, rate float64) *filter {
+	m := uint64(math.Ceil(-float64(capacity) * math.Log(rate) / (math.Ln2 * math.Ln2)))
+	k := uint64(math.Round(float64(m) / float64(capacity) * math.Ln2))
+	if k < 1 {
+		k = 1
+	}
+	return &filter{
+		bits:     make([]uint64, (m+63)/64),
+		m:        m,
+		k:        k,
+		capacity: capacity,
+	}
+}
+
+func (f *filter) full() bool {
+	return f.n >= f.capacity
+}
+
+// hashes returns the two 64-bit hashes that the bit positions of a hash are
+// derived from. The second one is odd so that the positions do not repeat.
+func hashes(h uint64) (h1, h2 uint64) {
+	return h, mix(h+0x9e3779b97f4a7c15) | 1
+}
+
+// position returns the i-th bit position of the hashes as h1 + i*h2, which is
+// as good as k independent hash functions. It is scaled to [0, m) by the high
+// bits of its product with m, which uses all of filters larger than 2^32 bits
+// unlike positions derived from 32-bit halves of a hash.
+func (f *filter) position(h1, h2, i uint64) uint64 {
+	hi, _ := bits.Mul64(h1+i*h2, f.m)
+	return hi
+}
+
+func (f *filter) add(h uint64) {
+	h1, h2 := hashes(h)
+	for i := uint64(0); i < f.k; i++ {
+		bit := f.position(h1, h2, i)
+		f.bits[bit/64] |= 1 << (bit % 64)
+	}
+	f.n++
+}
+
+func (f *filter) contains(h uint64) bool {
+	h1, h2 := hashes(h)
+	for i := uint64(0); i < f.k; i++ {
+		bit := f.position(h1, h2, i)
+		if f.bits[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
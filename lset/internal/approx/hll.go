@@ -0,0 +1,84 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package approx
+
+import (
+	"math"
+	"math/bits"
+)
+
+// precision is the number of bits of the hash that select a register. The
+// standard error of the estimates is 1.04/sqrt(2^precision), about 0.81%.
+const precision = 14
+
+const numRegisters = 1 << precision
+
+// HyperLogLog estimates the number of distinct strings added to it using a
+// fixed 16 KiB of memory.
+type HyperLogLog struct {
+	// registers hold the largest number of leading zeros plus one seen in
+	// the hashes that select each register.
+	registers [numRegisters]uint8
+}
+
+// NewHyperLogLog constructs an empty HyperLogLog.
+func NewHyperLogLog() *HyperLogLog {
+	return &HyperLogLog{}
+}
+
+// Add adds s.
+func (h *HyperLogLog) Add(s string) {
+	x := hash(s)
+	idx := x >> (64 - precision)
+	// The sentinel bit bounds the count when the remaining bits are all zero.
+	rho := uint8(bits.LeadingZeros64(x<<precision|1<<(precision-1))) + 1
+	if rho > h.registers[idx] {
+		h.registers[idx] = rho
+	}
+}
+
+// Merge adds the strings added to o, which then estimates the size of the
+// union.
+func (h *HyperLogLog) Merge(o *HyperLogLog) {
+	for i, r := range o.registers {
+		if r > h.registers[i] {
+			h.registers[i] = r
+		}
+	}
+}
+
+// Estimate returns the estimated number of distinct strings added.
+func (h *HyperLogLog) Estimate() int {
+	const m = float64(numRegisters)
+	sum := 0.0
+	zeros := 0
+	for _, r := range h.registers {
+		sum += math.Ldexp(1, -int(r))
+		if r == 0 {
+			zeros++
+		}
+	}
+	// The raw estimate is biased upwards for small cardinalities, which are
+	// more accurately estimated from the number of empty registers. Up to
+	// 3 times the number of registers, that estimate is within the standard
+	// error while the bias of the raw estimate is above it.
+	if zeros > 0 {
+		if est := m * math.Log(m/float64(zeros)); est <= 3*m {
+			return int(math.Round(est))
+		}
+	}
+	alpha := 0.7213 / (1 + 1.079/m)
+	return int(math.Round(alpha * m * m / sum))
+}
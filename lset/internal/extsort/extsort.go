@@ -0,0 +1,308 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package extsort sorts records that may not fit in memory. Records are
+// buffered in memory up to a size limit, after which the buffer is sorted and
+// written out to a temporary file as a chunk. The sorted chunks are then
+// merged when reading the records back.
+//
+// Sorting is stable, records with the same key are read back in the order they
+// were added.
+package extsort
+
+import (
+	"bufio"
+	"container/heap"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"unsafe"
+)
+
+// Record is a key and value pair that is sorted by key.
+type Record struct {
+	Key   string
+	Value string
+}
+
+// recordOverhead is the memory of a buffered record besides the bytes of its
+// key and value, which is the string headers of the record in the buffer.
+const recordOverhead = int(unsafe.Sizeof(Record{}))
+
+// size returns the memory of a buffered record.
+func (r Record) size() int {
+	return recordOverhead + len(r.Key) + len(r.Value)
+}
+
+// Sorter sorts records.
+type Sorter struct {
+	dir   string
+	limit int
+
+	buf    []Record
+	size   int
+	chunks []*os.File
+}
+
+// New constructs a Sorter that buffers up to limit bytes of records in memory,
+// counting their keys, values and the records themselves, before writing out
+// a chunk to a temporary file in dir. If dir is empty, the default directory
+// for temporary files is used.
+func New(dir string, limit int) *Sorter {
+	return &Sorter{dir: dir, limit: limit}
+}
+
+// Add adds a record.
+func (s *Sorter) Add(key, value string) error {
+	r := Record{Key: key, Value: value}
+	s.buf = append(s.buf, r)
+	s.size += r.size()
+	if s.size >= s.limit {
+		return s.spill()
+	}
+	return nil
+}
+
+// spill sorts the buffered records and writes them out as a chunk.
+func (s *Sorter) spill() error {
+	s.sortBuffer()
+	f, err := os.CreateTemp(s.dir, "extsort-")
+	if err != nil {
+		return err
+	}
+	s.chunks = append(s.chunks, f)
+	w := bufio.NewWriter(f)
+	for _, r := range s.buf {
+		if err := writeRecord(w, r); err != nil {
+			return fmt.Errorf("writing %s: %v", f.Name(), err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("writing %s: %v", f.Name(), err)
+	}
+	s.buf = s.buf[:0]
+	s.size = 0
+	return nil
+}
+
+func (s *Sorter) sortBuffer() {
+	sort.SliceStable(s.buf, func(i, j int) bool {
+		return s.buf[i].Key < s.buf[j].Key
+	})
+}
+
+// Sort finishes adding records and returns an iterator over the records in
+// sorted order. The Sorter must not be used after calling Sort.
+func (s *Sorter) Sort() (*Iterator, error) {
+	if len(s.chunks) == 0 {
+		s.sortBuffer()
+		return &Iterator{buf: s.buf}, nil
+	}
+	if len(s.buf) > 0 {
+		if err := s.spill(); err != nil {
+			s.Close()
+			return nil, err
+		}
+	}
+	s.buf = nil
+
+	it := &Iterator{files: s.chunks}
+	s.chunks = nil
+	for i, f := range it.files {
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			it.Close()
+			return nil, err
+		}
+		c := &chunk{r: bufio.NewReader(f), index: i}
+		if err := c.next(); err != nil {
+			if err == io.EOF {
+				continue
+			}
+			it.Close()
+			return nil, fmt.Errorf("reading %s: %v", f.Name(), err)
+		}
+		it.heap = append(it.heap, c)
+	}
+	heap.Init(&it.heap)
+	return it, nil
+}
+
+// Close removes temporary files of a Sorter that is not sorted.
+func (s *Sorter) Close() error {
+	return removeFiles(s.chunks)
+}
+
+// Iterator iterates over sorted records.
+type Iterator struct {
+	// buf holds the records if they all fit in memory.
+	buf []Record
+	// files are the chunks and heap merges the records of the chunks
+	// otherwise.
+	files []*os.File
+	heap  chunkHeap
+
+	started bool
+	rec     Record
+	err     error
+}
+
+// Next advances the iterator to the next record. It returns false when there
+// are no more records or on error.
+func (it *Iterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	if it.files == nil {
+		if it.started {
+			it.buf = it.buf[1:]
+		}
+		it.started = true
+		if len(it.buf) == 0 {
+			return false
+		}
+		it.rec = it.buf[0]
+		return true
+	}
+
+	if it.started && len(it.heap) > 0 {
+		c := it.heap[0]
+		if err := c.next(); err != nil {
+			if err != io.EOF {
+				it.err = fmt.Errorf("reading %s: %v", it.files[c.index].Name(), err)
+				return false
+			}
+			heap.Pop(&it.heap)
+		} else {
+			heap.Fix(&it.heap, 0)
+		}
+	}
+	it.started = true
+	if len(it.heap) == 0 {
+		return false
+	}
+	it.rec = it.heap[0].rec
+	return true
+}
+
+// Record returns the current record.
+func (it *Iterator) Record() Record {
+	return it.rec
+}
+
+// Err returns the first error encountered while iterating.
+func (it *Iterator) Err() error {
+	return it.err
+}
+
+// Close removes the temporary files.
+func (it *Iterator) Close() error {
+	err := removeFiles(it.files)
+	it.files = nil
+	it.heap = nil
+	return err
+}
+
+func removeFiles(files []*os.File) error {
+	var first error
+	for _, f := range files {
+		if err := f.Close(); err != nil && first == nil {
+			first = err
+		}
+		if err := os.Remove(f.Name()); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// writeRecord writes a record as the length of the key, the key, the length
+// of the value and the value.
+func writeRecord(w *bufio.Writer, r Record) error {
+	var lenBuf [binary.MaxVarintLen64]byte
+	for _, s := range []string{r.Key, r.Value} {
+		n := binary.PutUvarint(lenBuf[:], uint64(len(s)))
+		if _, err := w.Write(lenBuf[:n]); err != nil {
+			return err
+		}
+		if _, err := w.WriteString(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// chunk reads records from a chunk file.
+type chunk struct {
+	r     *bufio.Reader
+	index int
+	rec   Record
+}
+
+// next reads the next record. It returns io.EOF if there are no more records.
+func (c *chunk) next() error {
+	key, err := c.readString()
+	if err != nil {
+		return err
+	}
+	value, err := c.readString()
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return err
+	}
+	c.rec = Record{Key: key, Value: value}
+	return nil
+}
+
+func (c *chunk) readString() (string, error) {
+	n, err := binary.ReadUvarint(c.r)
+	if err != nil {
+		return "", err
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(c.r, buf); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return "", err
+	}
+	return string(buf), nil
+}
+
+// chunkHeap orders chunks by their current record. Ties are broken by the
+// order of the chunks to keep the sort stable.
+type chunkHeap []*chunk
+
+func (h chunkHeap) Len() int { return len(h) }
+
+func (h chunkHeap) Less(i, j int) bool {
+	if h[i].rec.Key != h[j].rec.Key {
+		return h[i].rec.Key < h[j].rec.Key
+	}
+	return h[i].index < h[j].index
+}
+
+func (h chunkHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *chunkHeap) Push(x any) { *h = append(*h, x.(*chunk)) }
+
+func (h *chunkHeap) Pop() any {
+	old := *h
+	c := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return c
+}
@@ -0,0 +1,104 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package extsort
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSort(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for _, conf := range []struct {
+		count int
+		limit int
+	}{
+		{count: 0, limit: 1 << 20},
+		{count: 1, limit: 1 << 20},
+		{count: 1000, limit: 1 << 20},
+		{count: 1, limit: 1},
+		{count: 1000, limit: 1},
+		{count: 1000, limit: 100},
+	} {
+		var input []Record
+		for i := 0; i < conf.count; i++ {
+			// Use few distinct keys to check that sorting is stable.
+			input = append(input, Record{
+				Key:   fmt.Sprintf("k%02d", rnd.Intn(50)),
+				Value: fmt.Sprintf("v%d", i),
+			})
+		}
+		want := append([]Record(nil), input...)
+		sort.SliceStable(want, func(i, j int) bool {
+			return want[i].Key < want[j].Key
+		})
+
+		dir := t.TempDir()
+		s := New(dir, conf.limit)
+		for _, r := range input {
+			if err := s.Add(r.Key, r.Value); err != nil {
+				t.Fatal(err)
+			}
+		}
+		it, err := s.Sort()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []Record
+		for it.Next() {
+			got = append(got, it.Record())
+		}
+		if err := it.Err(); err != nil {
+			t.Errorf("count %d limit %d: %v", conf.count, conf.limit, err)
+		}
+		if err := it.Close(); err != nil {
+			t.Errorf("count %d limit %d: Close: %v", conf.count, conf.limit, err)
+		}
+		if diff := cmp.Diff(got, want); diff != "" {
+			t.Errorf("count %d limit %d: diff %s", conf.count, conf.limit, diff)
+		}
+		files, err := os.ReadDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(files) != 0 {
+			t.Errorf("count %d limit %d: %d temporary files left", conf.count, conf.limit, len(files))
+		}
+	}
+}
+
+// TestSpillOverhead checks that the records themselves count towards the
+// limit, so that short records are not buffered far beyond it.
+func TestSpillOverhead(t *testing.T) {
+	const limit = 100 * recordOverhead
+	s := New(t.TempDir(), limit)
+	defer s.Close()
+	for i := 0; i < 1000; i++ {
+		if err := s.Add("", ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, want := len(s.chunks), 10; got != want {
+		t.Errorf("got %d chunks of empty records, want %d", got, want)
+	}
+	if s.size > limit {
+		t.Errorf("buffered %d bytes, want at most %d", s.size, limit)
+	}
+}
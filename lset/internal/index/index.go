@@ -0,0 +1,358 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package index reads and writes index files of line items. An index holds the
+// distinct keys of a file in sorted order with the first line item of each
+// key, so that it can be read back as a sorted stream without sorting or
+// looked up by key without reading it whole.
+//
+// An index file starts with a magic string and the key options that the keys
+// were built with, followed by the records of each key and line item. A table
+// at the end has the offset and first key of each block of records, which is
+// searched to find the block that may hold a key. The file ends with the
+// offset of the table and the number of records.
+package index
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// magic identifies index files and the version of their format.
+const magic = "lsetidx\x01"
+
+// blockSize is the number of records per entry in the table.
+const blockSize = 64
+
+// trailerSize is the size of the offset of the table and of the number of
+// records at the end of the file.
+const trailerSize = 16
+
+// ErrNotIndex is returned by Open for files that are not index files.
+var ErrNotIndex = errors.New("not an index file")
+
+// block is an entry of the table.
+type block struct {
+	offset   int64
+	firstKey string
+}
+
+// Writer writes an index.
+type Writer struct {
+	w      *bufio.Writer
+	offset int64
+	n      int
+	last   string
+	blocks []block
+	err    error
+}
+
+// NewWriter constructs a Writer that writes an index to w with the given key
+// options. The options are free-form and only used to check that the index is
+// used with the same options.
+func NewWriter(w io.Writer, options string) *Writer {
+	iw := &Writer{w: bufio.NewWriter(w)}
+	iw.write([]byte(magic))
+	iw.writeString(options)
+	return iw
+}
+
+func (w *Writer) write(b []byte) {
+	if w.err != nil {
+		return
+	}
+	n, err := w.w.Write(b)
+	w.offset += int64(n)
+	w.err = err
+}
+
+func (w *Writer) writeString(s string) {
+	var lenBuf [binary.MaxVarintLen64]byte
+	w.write(lenBuf[:binary.PutUvarint(lenBuf[:], uint64(len(s)))])
+	w.write([]byte(s))
+}
+
+// Add adds a key and its line item. Keys must be added in increasing order.
+// The line item is not stored if it is the key itself.
+func (w *Writer) Add(key, line string) error {
+	if w.n > 0 && key <= w.last {
+		return fmt.Errorf("key %q added after %q", key, w.last)
+	}
+	if w.n%blockSize == 0 {
+		w.blocks = append(w.blocks, block{offset: w.offset, firstKey: key})
+	}
+	if line == key {
+		line = ""
+	}
+	w.writeString(key)
+	w.writeString(line)
+	w.n++
+	w.last = key
+	return w.err
+}
+
+// Close writes the table and flushes the index. It does not close the
+// underlying writer.
+func (w *Writer) Close() error {
+	tableOffset := w.offset
+	var numBuf [binary.MaxVarintLen64]byte
+	for _, b := range w.blocks {
+		w.write(numBuf[:binary.PutUvarint(numBuf[:], uint64(b.offset))])
+		w.writeString(b.firstKey)
+	}
+	var trailer [trailerSize]byte
+	binary.LittleEndian.PutUint64(trailer[:8], uint64(tableOffset))
+	binary.LittleEndian.PutUint64(trailer[8:], uint64(w.n))
+	w.write(trailer[:])
+	if w.err != nil {
+		return w.err
+	}
+	return w.w.Flush()
+}
+
+// Index is an index file opened for reading.
+type Index struct {
+	f           *os.File
+	options     string
+	dataOffset  int64
+	tableOffset int64
+	n           int
+	blocks      []block
+}
+
+// Open opens the named index file. It returns ErrNotIndex if the file is not
+// an index file.
+func Open(filename string) (*Index, error) {
+	// Files that are not regular, such as named pipes, are not opened as
+	// opening them may block and reading them consumes their data.
+	fi, err := os.Stat(filename)
+	if err != nil {
+		return nil, err
+	}
+	if !fi.Mode().IsRegular() {
+		return nil, ErrNotIndex
+	}
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	x, err := open(f)
+	if err != nil {
+		f.Close()
+		if err == ErrNotIndex {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+	return x, nil
+}
+
+func open(f *os.File) (*Index, error) {
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if !fi.Mode().IsRegular() {
+		return nil, ErrNotIndex
+	}
+	size := fi.Size()
+	head := make([]byte, len(magic))
+	if _, err := io.ReadFull(f, head); err != nil || string(head) != magic {
+		return nil, ErrNotIndex
+	}
+	if size < int64(len(magic))+trailerSize {
+		return nil, errors.New("truncated index")
+	}
+
+	x := &Index{f: f}
+	r := &countingReader{r: bufio.NewReader(f), n: int64(len(magic))}
+	if x.options, err = readString(r); err != nil {
+		return nil, err
+	}
+	x.dataOffset = r.n
+
+	var trailer [trailerSize]byte
+	if _, err := f.ReadAt(trailer[:], size-trailerSize); err != nil {
+		return nil, err
+	}
+	x.tableOffset = int64(binary.LittleEndian.Uint64(trailer[:8]))
+	x.n = int(binary.LittleEndian.Uint64(trailer[8:]))
+	if x.tableOffset < x.dataOffset || x.tableOffset > size-trailerSize {
+		return nil, errors.New("invalid table offset")
+	}
+
+	tr := bufio.NewReader(io.NewSectionReader(f, x.tableOffset, size-trailerSize-x.tableOffset))
+	for i := 0; i < (x.n+blockSize-1)/blockSize; i++ {
+		offset, err := binary.ReadUvarint(tr)
+		if err != nil {
+			return nil, fmt.Errorf("reading table: %v", unexpected(err))
+		}
+		key, err := readString(tr)
+		if err != nil {
+			return nil, fmt.Errorf("reading table: %v", err)
+		}
+		x.blocks = append(x.blocks, block{offset: int64(offset), firstKey: key})
+	}
+	return x, nil
+}
+
+// Name returns the name of the index file.
+func (x *Index) Name() string {
+	return x.f.Name()
+}
+
+// Options returns the key options of the index.
+func (x *Index) Options() string {
+	return x.options
+}
+
+// Len returns the number of keys.
+func (x *Index) Len() int {
+	return x.n
+}
+
+// Lookup returns the line item of the key and whether the index contains the
+// key. Only the block of records that may hold the key is read.
+func (x *Index) Lookup(key string) (string, bool, error) {
+	i := sort.Search(len(x.blocks), func(i int) bool {
+		return x.blocks[i].firstKey > key
+	}) - 1
+	if i < 0 {
+		return "", false, nil
+	}
+	it := x.iter(x.blocks[i].offset, i*blockSize, min((i+1)*blockSize, x.n))
+	for it.Next() {
+		switch {
+		case it.Key() == key:
+			return it.Line(), true, nil
+		case it.Key() > key:
+			return "", false, nil
+		}
+	}
+	return "", false, it.Err()
+}
+
+// Iter returns an iterator over the keys in sorted order.
+func (x *Index) Iter() *Iterator {
+	return x.iter(x.dataOffset, 0, x.n)
+}
+
+// iter returns an iterator over records from start to end, where the record
+// at start is at offset.
+func (x *Index) iter(offset int64, start, end int) *Iterator {
+	return &Iterator{
+		r:    bufio.NewReader(io.NewSectionReader(x.f, offset, x.tableOffset-offset)),
+		left: end - start,
+	}
+}
+
+// Close closes the index file.
+func (x *Index) Close() error {
+	return x.f.Close()
+}
+
+// Iterator iterates over the keys of an index.
+type Iterator struct {
+	r         *bufio.Reader
+	left      int
+	key, line string
+	err       error
+}
+
+// Next advances the iterator to the next key. It returns false when there are
+// no more keys or on error.
+func (it *Iterator) Next() bool {
+	if it.err != nil || it.left == 0 {
+		return false
+	}
+	if it.key, it.err = readString(it.r); it.err != nil {
+		return false
+	}
+	if it.line, it.err = readString(it.r); it.err != nil {
+		return false
+	}
+	if it.line == "" {
+		it.line = it.key
+	}
+	it.left--
+	return true
+}
+
+// Key returns the current key.
+func (it *Iterator) Key() string {
+	return it.key
+}
+
+// Line returns the line item of the current key.
+func (it *Iterator) Line() string {
+	return it.line
+}
+
+// Err returns the error that stopped the iterator, if any.
+func (it *Iterator) Err() error {
+	return it.err
+}
+
+// reader is a reader of both bytes and byte slices, such as bufio.Reader.
+type reader interface {
+	io.Reader
+	io.ByteReader
+}
+
+// readString reads a string written as its length and bytes.
+func readString(r reader) (string, error) {
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return "", unexpected(err)
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return "", unexpected(err)
+	}
+	return string(buf), nil
+}
+
+// unexpected returns io.ErrUnexpectedEOF for io.EOF as the records that are
+// read are known to be there.
+func unexpected(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// countingReader counts the bytes read.
+type countingReader struct {
+	r *bufio.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func (c *countingReader) ReadByte() (byte, error) {
+	b, err := c.r.ReadByte()
+	if err == nil {
+		c.n++
+	}
+	return b, err
+}
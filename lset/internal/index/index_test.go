@@ -0,0 +1,136 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package index
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type record struct {
+	Key, Line string
+}
+
+func writeIndex(t *testing.T, dir string, options string, recs []record) string {
+	t.Helper()
+	filename := filepath.Join(dir, fmt.Sprintf("index%d", len(recs)))
+	f, err := os.Create(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	w := NewWriter(f, options)
+	for _, r := range recs {
+		if err := w.Add(r.Key, r.Line); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return filename
+}
+
+func TestIndex(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, n := range []int{0, 1, blockSize - 1, blockSize, 5*blockSize + 3} {
+		var recs []record
+		for i := 0; i < n; i++ {
+			// Every other line item is its own key.
+			key := fmt.Sprintf("key%05d", 2*i)
+			line := key
+			if i%2 == 1 {
+				line = fmt.Sprintf("%s value %d", key, i)
+			}
+			recs = append(recs, record{key, line})
+		}
+		filename := writeIndex(t, dir, "-k 1", recs)
+
+		x, err := Open(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := x.Options(); got != "-k 1" {
+			t.Errorf("n=%d: Options() = %q, want %q", n, got, "-k 1")
+		}
+		if got := x.Len(); got != n {
+			t.Errorf("n=%d: Len() = %d, want %d", n, got, n)
+		}
+
+		var got []record
+		it := x.Iter()
+		for it.Next() {
+			got = append(got, record{it.Key(), it.Line()})
+		}
+		if err := it.Err(); err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(got, recs); diff != "" {
+			t.Errorf("n=%d: Iter() diff %s", n, diff)
+		}
+
+		for i := -1; i <= 2*n; i++ {
+			key := fmt.Sprintf("key%05d", i)
+			if i < 0 {
+				key = ""
+			}
+			line, ok, err := x.Lookup(key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			wantOK := i >= 0 && i%2 == 0 && i < 2*n
+			wantLine := ""
+			if wantOK {
+				wantLine = recs[i/2].Line
+			}
+			if ok != wantOK || line != wantLine {
+				t.Errorf("n=%d: Lookup(%q) = %q, %t, want %q, %t", n, key, line, ok, wantLine, wantOK)
+			}
+		}
+		x.Close()
+	}
+}
+
+func TestWriterOrder(t *testing.T) {
+	w := NewWriter(io.Discard, "")
+	if err := w.Add("b", "b"); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"a", "b"} {
+		if err := w.Add(key, key); err == nil {
+			t.Errorf("Add(%q) after %q succeeded", key, "b")
+		}
+	}
+}
+
+func TestOpenNotIndex(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, content := range []string{"", "a\nb\n", "lsetidx"} {
+		filename := filepath.Join(dir, "text")
+		if err := os.WriteFile(filename, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Open(filename); err != ErrNotIndex {
+			t.Errorf("Open of %q: got error %v, want %v", content, err, ErrNotIndex)
+		}
+	}
+}
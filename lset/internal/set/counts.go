@@ -0,0 +1,103 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package set
+
+// Counts is a multiset of strings, also known as a bag, that keeps the number
+// of occurrences of each string. Strings with a count of zero are not in the
+// multiset.
+type Counts map[string]int
+
+// NewCounts constructs a Counts object.
+func NewCounts(strs ...string) Counts {
+	c := Counts{}
+	c.Add(strs...)
+	return c
+}
+
+// Add adds an occurrence of given string(s).
+func (c Counts) Add(strs ...string) {
+	for _, value := range strs {
+		c[value]++
+	}
+}
+
+// Count returns the number of occurrences of str.
+func (c Counts) Count(str string) int {
+	return c[str]
+}
+
+// Strings returns the set of distinct strings in c.
+func (c Counts) Strings() Strings {
+	s := make(Strings, len(c))
+	for str := range c {
+		s.Add(str)
+	}
+	return s
+}
+
+// Union returns the multiset union of c and o, where the count of each string
+// is the larger of its counts in c and o.
+func (c Counts) Union(o Counts) Counts {
+	ret := make(Counts, len(c))
+	for str, n := range c {
+		ret[str] = n
+	}
+	for str, n := range o {
+		if n > ret[str] {
+			ret[str] = n
+		}
+	}
+	return ret
+}
+
+// Intersect returns the multiset intersection of c and o, where the count of
+// each string is the smaller of its counts in c and o.
+func (c Counts) Intersect(o Counts) Counts {
+	ret := Counts{}
+	for str, n := range c {
+		if m := o[str]; m > 0 {
+			if m < n {
+				n = m
+			}
+			ret[str] = n
+		}
+	}
+	return ret
+}
+
+// Minus returns the multiset difference of c and o, where the count of each
+// string in c is reduced by its count in o.
+func (c Counts) Minus(o Counts) Counts {
+	ret := Counts{}
+	for str, n := range c {
+		if n -= o[str]; n > 0 {
+			ret[str] = n
+		}
+	}
+	return ret
+}
+
+// Sum returns the multiset sum of c and o, where the count of each string is
+// the sum of its counts in c and o.
+func (c Counts) Sum(o Counts) Counts {
+	ret := make(Counts, len(c))
+	for str, n := range c {
+		ret[str] = n
+	}
+	for str, n := range o {
+		ret[str] += n
+	}
+	return ret
+}
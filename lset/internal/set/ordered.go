@@ -0,0 +1,56 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package set
+
+// OrderedStrings is a set of strings that remembers the order in which
+// strings were first added.
+type OrderedStrings struct {
+	set   Strings
+	order []string
+}
+
+// NewOrderedStrings constructs an OrderedStrings object.
+func NewOrderedStrings(strs ...string) *OrderedStrings {
+	s := &OrderedStrings{set: make(Strings, len(strs))}
+	s.Add(strs...)
+	return s
+}
+
+// Add adds given string(s) that are not yet in the set to the end of the
+// order.
+func (s *OrderedStrings) Add(strs ...string) {
+	for _, value := range strs {
+		if !s.set.Contains(value) {
+			s.set.Add(value)
+			s.order = append(s.order, value)
+		}
+	}
+}
+
+// Contains returns true if string is in set, else false.
+func (s *OrderedStrings) Contains(str string) bool {
+	return s.set.Contains(str)
+}
+
+// Len returns the number of strings in the set.
+func (s *OrderedStrings) Len() int {
+	return len(s.order)
+}
+
+// Slice returns strings within set in the order they were first added. The
+// returned slice must not be modified.
+func (s *OrderedStrings) Slice() []string {
+	return s.order
+}
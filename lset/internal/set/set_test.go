@@ -16,6 +16,7 @@ package set
 
 import (
 	"sort"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -33,21 +34,21 @@ func TestStrings(t *testing.T) {
 		{
 			input: []string{"hello"},
 			want: Strings{
-				"hello": empty{},
+				"hello": struct{}{},
 			},
 		},
 		{
 			input: []string{"foo", "bar"},
 			want: Strings{
-				"bar": empty{},
-				"foo": empty{},
+				"bar": struct{}{},
+				"foo": struct{}{},
 			},
 		},
 		{
 			input: []string{"foo", "bar", "foo"},
 			want: Strings{
-				"bar": empty{},
-				"foo": empty{},
+				"bar": struct{}{},
+				"foo": struct{}{},
 			},
 		},
 	} {
@@ -67,22 +68,22 @@ func TestStringsAdd(t *testing.T) {
 			s:     NewStrings(),
 			input: "foo",
 			want: Strings{
-				"foo": empty{},
+				"foo": struct{}{},
 			},
 		},
 		{
 			s:     NewStrings("foo"),
 			input: "bar",
 			want: Strings{
-				"bar": empty{},
-				"foo": empty{},
+				"bar": struct{}{},
+				"foo": struct{}{},
 			},
 		},
 		{
 			s:     NewStrings("foo"),
 			input: "foo",
 			want: Strings{
-				"foo": empty{},
+				"foo": struct{}{},
 			},
 		},
 	} {
@@ -103,24 +104,24 @@ func TestStringsAddVarArgs(t *testing.T) {
 			s:     NewStrings(),
 			input: []string{"bar", "foo"},
 			want: Strings{
-				"bar": empty{},
-				"foo": empty{},
+				"bar": struct{}{},
+				"foo": struct{}{},
 			},
 		},
 		{
 			s:     NewStrings("foo"),
 			input: []string{"bar"},
 			want: Strings{
-				"bar": empty{},
-				"foo": empty{},
+				"bar": struct{}{},
+				"foo": struct{}{},
 			},
 		},
 		{
 			s:     NewStrings("bar", "foo"),
 			input: []string{"bar"},
 			want: Strings{
-				"bar": empty{},
-				"foo": empty{},
+				"bar": struct{}{},
+				"foo": struct{}{},
 			},
 		},
 	} {
@@ -141,33 +142,33 @@ func TestStringsAddSet(t *testing.T) {
 			s1: NewStrings(),
 			s2: NewStrings("a", "b"),
 			want: Strings{
-				"a": empty{},
-				"b": empty{},
+				"a": struct{}{},
+				"b": struct{}{},
 			},
 		},
 		{
 			s1: NewStrings("a", "b"),
 			s2: NewStrings(),
 			want: Strings{
-				"a": empty{},
-				"b": empty{},
+				"a": struct{}{},
+				"b": struct{}{},
 			},
 		},
 		{
 			s1: NewStrings("a"),
 			s2: NewStrings("b"),
 			want: Strings{
-				"a": empty{},
-				"b": empty{},
+				"a": struct{}{},
+				"b": struct{}{},
 			},
 		},
 		{
 			s1: NewStrings("a", "b"),
 			s2: NewStrings("b", "c"),
 			want: Strings{
-				"a": empty{},
-				"b": empty{},
-				"c": empty{},
+				"a": struct{}{},
+				"b": struct{}{},
+				"c": struct{}{},
 			},
 		},
 	} {
@@ -198,7 +199,7 @@ func TestStringsRemove(t *testing.T) {
 			s:     NewStrings("bar", "foo"),
 			input: "bar",
 			want: Strings{
-				"foo": empty{},
+				"foo": struct{}{},
 			},
 		},
 	} {
@@ -224,7 +225,7 @@ func TestStringsRemoveVarArgs(t *testing.T) {
 			s:     NewStrings("bar", "foo", "qux"),
 			input: []string{"bar", "foo"},
 			want: Strings{
-				"qux": empty{},
+				"qux": struct{}{},
 			},
 		},
 	} {
@@ -250,8 +251,8 @@ func TestStringsRemoveSet(t *testing.T) {
 			s1: NewStrings("a", "b"),
 			s2: NewStrings(),
 			want: Strings{
-				"a": empty{},
-				"b": empty{},
+				"a": struct{}{},
+				"b": struct{}{},
 			},
 		},
 		{
@@ -263,14 +264,14 @@ func TestStringsRemoveSet(t *testing.T) {
 			s1: NewStrings("a"),
 			s2: NewStrings("b"),
 			want: Strings{
-				"a": empty{},
+				"a": struct{}{},
 			},
 		},
 		{
 			s1: NewStrings("a", "b"),
 			s2: NewStrings("b", "c"),
 			want: Strings{
-				"a": empty{},
+				"a": struct{}{},
 			},
 		},
 	} {
@@ -387,7 +388,7 @@ func TestStringsSortedSlice(t *testing.T) {
 		{"z", "a", "j"},
 	} {
 		s := NewStrings(want...)
-		got := s.SortedSlice()
+		got := s.SortedSlice(strings.Compare)
 		// Sort the input before comparison as the returned slice should already be sorted.
 		sort.Strings(want)
 		if diff := cmp.Diff(got, want); diff != "" {
@@ -481,3 +482,255 @@ func TestStringsDiff(t *testing.T) {
 		}
 	}
 }
+
+func TestStringsUnion(t *testing.T) {
+	for i, conf := range []struct {
+		s1   Strings
+		s2   Strings
+		want Strings
+	}{
+		{
+			s1:   NewStrings(),
+			s2:   NewStrings(),
+			want: NewStrings(),
+		},
+		{
+			s1:   NewStrings(),
+			s2:   NewStrings("a"),
+			want: NewStrings("a"),
+		},
+		{
+			s1:   NewStrings("a", "b"),
+			s2:   NewStrings(),
+			want: NewStrings("a", "b"),
+		},
+		{
+			s1:   NewStrings("a", "b"),
+			s2:   NewStrings("b", "c"),
+			want: NewStrings("a", "b", "c"),
+		},
+	} {
+		s1 := NewStrings(conf.s1.Slice()...)
+		if diff := cmp.Diff(conf.s1.Union(conf.s2), conf.want); diff != "" {
+			t.Errorf("testcase %d\n%s", i, diff)
+		}
+		// Union should not modify the receiver.
+		if diff := cmp.Diff(conf.s1, s1); diff != "" {
+			t.Errorf("testcase %d: receiver modified\n%s", i, diff)
+		}
+	}
+}
+
+func TestStringsSymmetricDifference(t *testing.T) {
+	for i, conf := range []struct {
+		s1   Strings
+		s2   Strings
+		want Strings
+	}{
+		{
+			s1:   NewStrings(),
+			s2:   NewStrings(),
+			want: NewStrings(),
+		},
+		{
+			s1:   NewStrings("a"),
+			s2:   NewStrings("a"),
+			want: NewStrings(),
+		},
+		{
+			s1:   NewStrings(),
+			s2:   NewStrings("a"),
+			want: NewStrings("a"),
+		},
+		{
+			s1:   NewStrings("a", "b"),
+			s2:   NewStrings(),
+			want: NewStrings("a", "b"),
+		},
+		{
+			s1:   NewStrings("a", "b"),
+			s2:   NewStrings("b", "c"),
+			want: NewStrings("a", "c"),
+		},
+		{
+			s1:   NewStrings("a", "b", "c", "d"),
+			s2:   NewStrings("a", "b", "c"),
+			want: NewStrings("d"),
+		},
+	} {
+		if diff := cmp.Diff(conf.s1.SymmetricDifference(conf.s2), conf.want); diff != "" {
+			t.Errorf("testcase %d\n%s", i, diff)
+		}
+	}
+}
+
+func TestOrderedStrings(t *testing.T) {
+	for _, conf := range []struct {
+		input []string
+		want  []string
+	}{
+		{
+			input: nil,
+			want:  nil,
+		},
+		{
+			input: []string{"foo"},
+			want:  []string{"foo"},
+		},
+		{
+			input: []string{"foo", "bar"},
+			want:  []string{"foo", "bar"},
+		},
+		{
+			input: []string{"foo", "bar", "foo", "qux", "bar"},
+			want:  []string{"foo", "bar", "qux"},
+		},
+	} {
+		s := NewOrderedStrings(conf.input...)
+		if diff := cmp.Diff(s.Slice(), conf.want); diff != "" {
+			t.Errorf("input %v: diff %s", conf.input, diff)
+		}
+		if got := s.Len(); got != len(conf.want) {
+			t.Errorf("input %v: got len %d, want %d", conf.input, got, len(conf.want))
+		}
+	}
+}
+
+func TestOrderedStringsAdd(t *testing.T) {
+	for _, conf := range []struct {
+		s     *OrderedStrings
+		input []string
+		want  []string
+	}{
+		{
+			s:     NewOrderedStrings(),
+			input: []string{"b", "a"},
+			want:  []string{"b", "a"},
+		},
+		{
+			s:     NewOrderedStrings("c"),
+			input: []string{"b", "c", "a"},
+			want:  []string{"c", "b", "a"},
+		},
+	} {
+		conf.s.Add(conf.input...)
+		if diff := cmp.Diff(conf.s.Slice(), conf.want); diff != "" {
+			t.Errorf("input %v: diff %s", conf.input, diff)
+		}
+	}
+}
+
+func TestOrderedStringsContains(t *testing.T) {
+	for _, conf := range []struct {
+		s     *OrderedStrings
+		input string
+		want  bool
+	}{
+		{
+			s:     NewOrderedStrings(),
+			input: "bar",
+			want:  false,
+		},
+		{
+			s:     NewOrderedStrings("foo", "bar"),
+			input: "bar",
+			want:  true,
+		},
+		{
+			s:     NewOrderedStrings("foo"),
+			input: "qux",
+			want:  false,
+		},
+	} {
+		if got := conf.s.Contains(conf.input); got != conf.want {
+			t.Errorf("input %q: got %v, want %v", conf.input, got, conf.want)
+		}
+	}
+}
+
+func TestCounts(t *testing.T) {
+	for _, conf := range []struct {
+		input []string
+		want  Counts
+	}{
+		{
+			input: nil,
+			want:  Counts{},
+		},
+		{
+			input: []string{"foo", "bar", "foo"},
+			want: Counts{
+				"bar": 1,
+				"foo": 2,
+			},
+		},
+	} {
+		if diff := cmp.Diff(NewCounts(conf.input...), conf.want); diff != "" {
+			t.Errorf("input %v: diff %s", conf.input, diff)
+		}
+	}
+}
+
+func TestCountsStrings(t *testing.T) {
+	c := NewCounts("a", "b", "a")
+	if diff := cmp.Diff(c.Strings(), NewStrings("a", "b")); diff != "" {
+		t.Errorf("diff %s", diff)
+	}
+}
+
+func TestCountsOperations(t *testing.T) {
+	for i, conf := range []struct {
+		c1            Counts
+		c2            Counts
+		wantUnion     Counts
+		wantIntersect Counts
+		wantMinus     Counts
+		wantSum       Counts
+	}{
+		{
+			c1:            NewCounts(),
+			c2:            NewCounts(),
+			wantUnion:     Counts{},
+			wantIntersect: Counts{},
+			wantMinus:     Counts{},
+			wantSum:       Counts{},
+		},
+		{
+			c1:            NewCounts("a", "a"),
+			c2:            NewCounts(),
+			wantUnion:     Counts{"a": 2},
+			wantIntersect: Counts{},
+			wantMinus:     Counts{"a": 2},
+			wantSum:       Counts{"a": 2},
+		},
+		{
+			c1:            NewCounts(),
+			c2:            NewCounts("a"),
+			wantUnion:     Counts{"a": 1},
+			wantIntersect: Counts{},
+			wantMinus:     Counts{},
+			wantSum:       Counts{"a": 1},
+		},
+		{
+			c1:            NewCounts("a", "a", "a", "b", "c"),
+			c2:            NewCounts("a", "b", "b", "d"),
+			wantUnion:     Counts{"a": 3, "b": 2, "c": 1, "d": 1},
+			wantIntersect: Counts{"a": 1, "b": 1},
+			wantMinus:     Counts{"a": 2, "c": 1},
+			wantSum:       Counts{"a": 4, "b": 3, "c": 1, "d": 1},
+		},
+	} {
+		if diff := cmp.Diff(conf.c1.Union(conf.c2), conf.wantUnion); diff != "" {
+			t.Errorf("testcase %d\nunion: %s", i, diff)
+		}
+		if diff := cmp.Diff(conf.c1.Intersect(conf.c2), conf.wantIntersect); diff != "" {
+			t.Errorf("testcase %d\nintersect: %s", i, diff)
+		}
+		if diff := cmp.Diff(conf.c1.Minus(conf.c2), conf.wantMinus); diff != "" {
+			t.Errorf("testcase %d\nminus: %s", i, diff)
+		}
+		if diff := cmp.Diff(conf.c1.Sum(conf.c2), conf.wantSum); diff != "" {
+			t.Errorf("testcase %d\nsum: %s", i, diff)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+// keyFunc returns the key of a line item that is used for set membership.
+type keyFunc func(line string) string
+
+// fieldKey returns a keyFunc that uses the given 1-based field as key. Fields
+// are separated by delim, or by runs of whitespace if delim is empty. Lines
+// with fewer fields are their own keys.
+func fieldKey(field int, delim string) keyFunc {
+	return func(line string) string {
+		var fields []string
+		if delim == "" {
+			fields = strings.Fields(line)
+		} else {
+			fields = strings.SplitN(line, delim, field+1)
+		}
+		if field > len(fields) {
+			return line
+		}
+		return fields[field-1]
+	}
+}
+
+// regexpKey returns a keyFunc that uses the first capture group of re as key,
+// or the whole match if re has no capture groups. Lines that do not match are
+// their own keys.
+func regexpKey(re *regexp.Regexp) keyFunc {
+	group := 0
+	if re.NumSubexp() > 0 {
+		group = 1
+	}
+	return func(line string) string {
+		m := re.FindStringSubmatchIndex(line)
+		if m == nil || m[2*group] < 0 {
+			return line
+		}
+		return line[m[2*group]:m[2*group+1]]
+	}
+}
+
+// normalizer returns the normalized form of a key.
+type normalizer func(string) string
+
+// normalizers returns the enabled normalizers in the order they are applied.
+func normalizers(trim, squeeze, nfc, fold bool) []normalizer {
+	var ns []normalizer
+	if trim {
+		ns = append(ns, strings.TrimSpace)
+	}
+	if squeeze {
+		ns = append(ns, func(s string) string {
+			return strings.Join(strings.Fields(s), " ")
+		})
+	}
+	if nfc {
+		ns = append(ns, norm.NFC.String)
+	}
+	if fold {
+		// A Caser may be stateful and must not be shared by the goroutines
+		// that compute keys, hence each call takes one from a pool.
+		casers := sync.Pool{New: func() any {
+			c := cases.Fold()
+			return &c
+		}}
+		ns = append(ns, func(s string) string {
+			c := casers.Get().(*cases.Caser)
+			defer casers.Put(c)
+			return c.String(s)
+		})
+	}
+	return ns
+}
+
+// normalizeKey returns a keyFunc that applies the normalizers to the keys
+// returned by key. If key is nil, lines are used as keys.
+func normalizeKey(key keyFunc, ns []normalizer) keyFunc {
+	if len(ns) == 0 {
+		return key
+	}
+	return func(line string) string {
+		k := line
+		if key != nil {
+			k = key(line)
+		}
+		for _, n := range ns {
+			k = n(k)
+		}
+		return k
+	}
+}
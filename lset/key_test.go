@@ -0,0 +1,131 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestFieldKey(t *testing.T) {
+	for _, conf := range []struct {
+		field int
+		delim string
+		line  string
+		want  string
+	}{
+		{field: 1, line: "a b c", want: "a"},
+		{field: 2, line: "  a \t b  c", want: "b"},
+		{field: 3, line: "a b c", want: "c"},
+		// Lines without the field are their own keys.
+		{field: 4, line: "a b c", want: "a b c"},
+		{field: 1, line: "", want: ""},
+		{field: 2, line: "   ", want: "   "},
+
+		{field: 2, delim: ",", line: "a,b,c", want: "b"},
+		{field: 3, delim: ",", line: "a,b,c,d", want: "c"},
+		{field: 2, delim: ",", line: "a, b c,d", want: " b c"},
+		{field: 2, delim: ",", line: "a,,c", want: ""},
+		{field: 2, delim: "::", line: "a::b:c::d", want: "b:c"},
+		{field: 3, delim: ",", line: "a,b", want: "a,b"},
+		{field: 2, delim: ",", line: "a b", want: "a b"},
+	} {
+		if got := fieldKey(conf.field, conf.delim)(conf.line); got != conf.want {
+			t.Errorf("fieldKey(%d, %q)(%q) = %q, want %q", conf.field, conf.delim, conf.line, got, conf.want)
+		}
+	}
+}
+
+func TestRegexpKey(t *testing.T) {
+	for _, conf := range []struct {
+		re   string
+		line string
+		want string
+	}{
+		{re: `id=(\d+)`, line: "x id=42 y", want: "42"},
+		// Only the first group is the key.
+		{re: `(\w+)=(\d+)`, line: "id=42", want: "id"},
+		{re: `(?:id|key)=(\d+)`, line: "key=7", want: "7"},
+		// Without capture groups, the whole match is the key.
+		{re: `\d+`, line: "id 42 x", want: "42"},
+		{re: `[a-z]+@[a-z.]+`, line: "to: bob@example.com;", want: "bob@example.com"},
+		// Lines whose group does not match are their own keys.
+		{re: `^(\w+)?:`, line: ":x", want: ":x"},
+		{re: `^(\w+)?:`, line: "a:x", want: "a"},
+		{re: `(a)|b`, line: "xbx", want: "xbx"},
+		// Lines that do not match are their own keys.
+		{re: `id=(\d+)`, line: "no id here", want: "no id here"},
+		{re: `\d+`, line: "", want: ""},
+		// An empty group is an empty key.
+		{re: `id=(\d*)`, line: "id=", want: ""},
+	} {
+		if got := regexpKey(regexp.MustCompile(conf.re))(conf.line); got != conf.want {
+			t.Errorf("regexpKey(%q)(%q) = %q, want %q", conf.re, conf.line, got, conf.want)
+		}
+	}
+}
+
+func TestNormalizeKey(t *testing.T) {
+	for _, conf := range []struct {
+		desc                     string
+		key                      keyFunc
+		trim, squeeze, nfc, fold bool
+		line                     string
+		want                     string
+	}{
+		{desc: "none", line: " A\r", want: " A\r"},
+		{desc: "fold", fold: true, line: "HeLLo", want: "hello"},
+		{desc: "fold", fold: true, line: "Straße", want: "strasse"},
+		{desc: "fold", fold: true, line: "ΣΑΣ", want: "σασ"},
+		{desc: "trim", trim: true, line: "  a b \t", want: "a b"},
+		{desc: "trim CRLF", trim: true, line: "a b\r", want: "a b"},
+		{desc: "squeeze", squeeze: true, line: "a  \t b   c", want: "a b c"},
+		{desc: "squeeze", squeeze: true, line: " a  b\r", want: "a b"},
+		{desc: "nfc of NFC", nfc: true, line: "caf\u00e9", want: "caf\u00e9"},
+		{desc: "nfc of NFD", nfc: true, line: "cafe\u0301", want: "caf\u00e9"},
+		{desc: "NFD without nfc", line: "cafe\u0301", want: "cafe\u0301"},
+		{desc: "all", trim: true, squeeze: true, nfc: true, fold: true, line: " CAFE\u0301  Bar\r", want: "caf\u00e9 bar"},
+		{desc: "field", key: fieldKey(2, ","), trim: true, fold: true, line: "1, Foo ,2", want: "foo"},
+	} {
+		key := normalizeKey(conf.key, normalizers(conf.trim, conf.squeeze, conf.nfc, conf.fold))
+		got := conf.line
+		if key != nil {
+			got = key(conf.line)
+		}
+		if got != conf.want {
+			t.Errorf("%s: key(%q) = %q, want %q", conf.desc, conf.line, got, conf.want)
+		}
+	}
+}
+
+// TestNormalizedLines checks that the first spelling of a key in the files is
+// printed.
+func TestNormalizedLines(t *testing.T) {
+	dir := t.TempDir()
+	filenames := writeFiles(t, dir, "Foo\nfoo\n cafe\u0301\n", "FOO\ncaf\u00e9\nbar \n")
+	key := normalizeKey(nil, normalizers(true, false, true, true))
+	for _, conf := range []struct {
+		cmd  string
+		want string
+	}{
+		{cmd: "union", want: "bar \n cafe\u0301\nFoo\n"},
+		{cmd: "cross", want: " cafe\u0301\nFoo\n"},
+		{cmd: "diff", want: "+bar \n"},
+	} {
+		if got := memoryOutput(t, conf.cmd, filenames, readOptions{key: key}); got != conf.want {
+			t.Errorf("%s = %q, want %q", conf.cmd, got, conf.want)
+		}
+	}
+}
@@ -12,103 +12,646 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-// The lset command line tool provides set operations between 2 files, where
+// The lset command line tool provides set operations between files, where
 // each input file is a set of line items. If a file has duplicated line items,
 // those will be treated as the same value. Resulting values are printed to
 // stdout where each value is a line.
+//
+// Except for diff, comm, stats, index and the subset, superset and disjoint
+// predicates, commands accept any number of files and fold the operation over
+// them from left to right.
 package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
 
+	"github.com/cybrcodr/txttools/internal/infile"
+	"github.com/cybrcodr/txttools/internal/lines"
+	"github.com/cybrcodr/txttools/lset/internal/index"
 	"github.com/cybrcodr/txttools/lset/internal/set"
 )
 
+var (
+	keyField = flag.Int("k", 0, "compare lines on this field only, first field is 1")
+	keyDelim = flag.String("d", "", "field delimiter for -k, default is runs of whitespace")
+	keyRegex = flag.String("re", "", "compare lines on the first capture group of this regular expression,\nor the whole match if it has no groups")
+
+	ignoreCase = flag.Bool("i", false, "compare keys case-insensitively")
+	trim       = flag.Bool("trim", false, "ignore leading and trailing whitespace of keys")
+	squeeze    = flag.Bool("squeeze", false, "treat runs of whitespace in keys as a single space")
+	nfc        = flag.Bool("nfc", false, "compare keys in Unicode normalization form C")
+
+	order = flag.String("order", "sorted", "order of output lines, one of sorted, file1, file2, ..., fileN or none")
+	bag   = flag.Bool("bag", false, "treat files as multisets and print the count of each line")
+
+	spill     = flag.Bool("spill", false, "sort files in chunks on disk for files larger than memory")
+	spillSize = flag.Int("spill-size", 256, "with -spill, MiB of each file to buffer in memory before writing to disk")
+	tmpDir    = flag.String("tmpdir", "", "directory for temporary files, default is the system temporary directory")
+	sorted    = flag.Bool("sorted", false, "files are already sorted by key, merge them without holding them in memory")
+
+	approxMode = flag.Bool("approx", false, "approximate minus and cross in a few bytes per line and stats in fixed memory")
+	fpRate     = flag.Float64("fp-rate", 0.01, "with -approx, false positive rate of minus and cross")
+
+	hide1 = flag.Bool("1", false, "with comm, suppress lines only in file1")
+	hide2 = flag.Bool("2", false, "with comm, suppress lines only in file2")
+	hide3 = flag.Bool("3", false, "with comm, suppress lines in both files")
+
+	format = flag.String("o", "text", "output format, one of text, json, ndjson or csv")
+
+	verbose = flag.Bool("v", false, "with subset, superset and disjoint, print the lines that make them false")
+
+	maxLine   = flag.Int("max-line", 0, "maximum length of a line in bytes, 0 for no limit")
+	nul       = flag.Bool("z", false, `lines are terminated by NUL instead of newline, same as -rs '\0' -ors '\0'`)
+	recordSep = flag.String("rs", "\n", `separator of input lines, which may have escapes such as \t and \0 for NUL`)
+	outputSep = flag.String("ors", "\n", "terminator of output lines in text format, which may have escapes")
+)
+
+const (
+	orderSorted = -1
+	orderNone   = -2
+)
+
+// outputOrder is either orderSorted, orderNone or the 0-based index of the
+// input whose order is followed first.
+var outputOrder = orderSorted
+
+// commColumns are the columns printed by comm, which are lines only in file1,
+// lines only in file2 and lines in both files.
+var commColumns = [3]bool{true, true, true}
+
+// lineEnd is the terminator of output lines in text format.
+var lineEnd = "\n"
+
+// holds is whether the predicate of subset, superset or disjoint holds.
+var holds = true
+
+// errorStatus is the exit status on errors, which is 2 for predicates as 1
+// means that the predicate does not hold.
+var errorStatus = 1
+
 func main() {
-	if len(os.Args) != 4 {
+	flag.Usage = usage
+	flag.Parse()
+	args := flag.Args()
+	predicate := len(args) > 0 && (args[0] == "subset" || args[0] == "superset" || args[0] == "disjoint")
+	if predicate {
+		errorStatus = 2
+	}
+	if len(args) < 3 {
 		usage()
-		os.Exit(1)
+		os.Exit(errorStatus)
 	}
-	var cmd func(set.Strings, set.Strings)
-	switch os.Args[1] {
+	// bagCmd is the variant of cmd for multisets.
+	var cmd, bagCmd func(printer, []*input)
+	pairwise := false
+	switch args[0] {
 	case "diff":
-		cmd = diff
+		cmd, bagCmd = diff, bagDiff
+		pairwise = true
+	case "comm":
+		cmd = comm
+		pairwise = true
+	case "union":
+		cmd, bagCmd = union, bagUnion
+	case "xor":
+		cmd = xor
 	case "cross":
-		cmd = intersect
+		cmd, bagCmd = intersect, bagIntersect
 	case "minus":
-		cmd = subtract
+		cmd, bagCmd = subtract, bagSubtract
+	case "sum":
+		bagCmd = sum
+	case "count":
+		cmd = count
+	case "stats":
+		cmd = stats
+		pairwise = true
+	case "index":
+		pairwise = true
+	case "subset":
+		cmd = subset
+		pairwise = true
+	case "superset":
+		cmd = superset
+		pairwise = true
+	case "disjoint":
+		cmd = disjoint
+		pairwise = true
 	default:
-		fmt.Fprintf(os.Stderr, "Invalid command %q\n", os.Args[1])
+		fmt.Fprintf(os.Stderr, "Invalid command %q\n", args[0])
 		usage()
-		os.Exit(1)
+		os.Exit(errorStatus)
+	}
+	if *bag {
+		if bagCmd == nil {
+			fmt.Fprintf(os.Stderr, "Command %q does not support -bag\n", args[0])
+			os.Exit(errorStatus)
+		}
+		cmd = bagCmd
+	} else if cmd == nil && args[0] != "index" {
+		fmt.Fprintf(os.Stderr, "Command %q requires -bag\n", args[0])
+		os.Exit(errorStatus)
 	}
 
-	set1, err := readFile(os.Args[2])
+	filenames, err := infile.Expand(args[1:])
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		os.Exit(errorStatus)
+	}
+	if pairwise && len(filenames) != 2 {
+		fmt.Fprintf(os.Stderr, "Command %q requires exactly 2 files\n", args[0])
+		usage()
+		os.Exit(errorStatus)
+	}
+	commColumns = [3]bool{!*hide1, !*hide2, !*hide3}
+
+	stdin := 0
+	for _, filename := range filenames {
+		if filename == "-" {
+			stdin++
+		}
+	}
+	if stdin > 1 {
+		fmt.Fprintln(os.Stderr, "Only one file can be '-'")
+		os.Exit(errorStatus)
+	}
+
+	var key keyFunc
+	switch {
+	case *keyField != 0 && *keyRegex != "":
+		fmt.Fprintln(os.Stderr, "Only one of -k or -re can be specified")
+		os.Exit(errorStatus)
+	case *keyField < 0:
+		fmt.Fprintf(os.Stderr, "Invalid -k value %d\n", *keyField)
+		os.Exit(errorStatus)
+	case *keyField > 0:
+		key = fieldKey(*keyField, *keyDelim)
+	case *keyRegex != "":
+		re, err := regexp.Compile(*keyRegex)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid -re value: %v\n", err)
+			os.Exit(errorStatus)
+		}
+		key = regexpKey(re)
 	}
-	set2, err := readFile(os.Args[3])
+	key = normalizeKey(key, normalizers(*trim, *squeeze, *nfc, *ignoreCase))
+
+	switch {
+	case *order == "sorted":
+	case *order == "none":
+		outputOrder = orderNone
+	case strings.HasPrefix(*order, "file"):
+		n, err := strconv.Atoi(strings.TrimPrefix(*order, "file"))
+		if err != nil || n < 1 || n > len(filenames) {
+			fmt.Fprintf(os.Stderr, "Invalid -order value %q\n", *order)
+			os.Exit(errorStatus)
+		}
+		outputOrder = n - 1
+	default:
+		fmt.Fprintf(os.Stderr, "Invalid -order value %q\n", *order)
+		os.Exit(errorStatus)
+	}
+
+	if *maxLine < 0 {
+		fmt.Fprintf(os.Stderr, "Invalid -max-line value %d\n", *maxLine)
+		os.Exit(errorStatus)
+	}
+	if *nul {
+		*recordSep, *outputSep = `\0`, `\0`
+	}
+	sep, err := lines.ParseSeparator(*recordSep)
+	if err != nil || sep == "" {
+		fmt.Fprintf(os.Stderr, "Invalid -rs value %q\n", *recordSep)
+		os.Exit(errorStatus)
+	}
+	if lineEnd, err = lines.ParseSeparator(*outputSep); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid -ors value %q\n", *outputSep)
+		os.Exit(errorStatus)
+	}
+	opts := readOptions{
+		key:        key,
+		trackOrder: outputOrder >= 0,
+		bag:        *bag,
+		maxLine:    *maxLine,
+		sep:        sep,
+	}
+	if *spillSize < 1 {
+		fmt.Fprintf(os.Stderr, "Invalid -spill-size value %d\n", *spillSize)
+		os.Exit(errorStatus)
+	}
+	if args[0] == "index" {
+		if err := buildIndex(filenames[0], filenames[1], opts, keySpec(), *tmpDir, *spillSize<<20); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(errorStatus)
+		}
+		return
+	}
+	indexes, err := openIndexes(filenames, keySpec())
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
+		os.Exit(errorStatus)
+	}
+	defer closeIndexes(indexes)
+	hasIndex := false
+	for _, x := range indexes {
+		if x != nil {
+			hasIndex = true
+		}
+	}
+
+	var out io.Writer = os.Stdout
+	if predicate && !*verbose {
+		out = io.Discard
+	}
+	w := bufio.NewWriter(out)
+	p, err := newPrinter(w, *format, args[0], filenames, *bag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid -o value: %v\n", err)
+		os.Exit(errorStatus)
+	}
+
+	if *approxMode {
+		switch {
+		case *spill || *sorted:
+			fmt.Fprintln(os.Stderr, "-approx cannot be used with -spill or -sorted")
+			os.Exit(errorStatus)
+		case hasIndex:
+			fmt.Fprintln(os.Stderr, "-approx does not support index files")
+			os.Exit(errorStatus)
+		case !approxCommands[args[0]] || *bag:
+			fmt.Fprintf(os.Stderr, "Command %q does not support -approx\n", args[0])
+			os.Exit(errorStatus)
+		case outputOrder != orderSorted:
+			fmt.Fprintln(os.Stderr, "-approx does not support -order")
+			os.Exit(errorStatus)
+		case *fpRate <= 0 || *fpRate >= 1:
+			fmt.Fprintf(os.Stderr, "Invalid -fp-rate value %v\n", *fpRate)
+			os.Exit(errorStatus)
+		}
+		if err := approxCommand(p, args[0], filenames, opts, *fpRate); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(errorStatus)
+		}
+	} else if *spill || *sorted || hasIndex {
+		switch {
+		case *spill && *sorted:
+			fmt.Fprintln(os.Stderr, "Only one of -spill or -sorted can be specified")
+			os.Exit(errorStatus)
+		case (!mergeCommands[args[0]] || *bag) && hasIndex:
+			fmt.Fprintf(os.Stderr, "Command %q does not support index files\n", args[0])
+			os.Exit(errorStatus)
+		case !mergeCommands[args[0]] || *bag:
+			fmt.Fprintf(os.Stderr, "Command %q does not support -spill or -sorted\n", args[0])
+			os.Exit(errorStatus)
+		case outputOrder != orderSorted:
+			fmt.Fprintln(os.Stderr, "-spill, -sorted and index files only support sorted output")
+			os.Exit(errorStatus)
+		}
+		if err := runMerge(p, args[0], filenames, indexes, opts); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(errorStatus)
+		}
+	} else {
+		inputs, err := readFiles(filenames, opts)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(errorStatus)
+		}
+		cmd(p, inputs)
+	}
+
+	if err := p.close(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(errorStatus)
+	}
+	if err := w.Flush(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(errorStatus)
+	}
+	if !holds {
 		os.Exit(1)
 	}
+}
+
+// runMerge runs the named command by merging streams of files that are either
+// sorted on disk with -spill, already sorted with -sorted or indexes. If the
+// command can be done by looking up keys in the indexes, they are not merged.
+func runMerge(p printer, name string, filenames []string, indexes []*index.Index, opts readOptions) error {
+	var streams []stream
+	defer func() {
+		for _, s := range streams {
+			s.Close()
+		}
+	}()
+	for i, filename := range filenames {
+		var s stream
+		var err error
+		switch {
+		case indexes[i] != nil:
+			s = &indexStream{x: indexes[i], it: indexes[i].Iter()}
+		case *sorted:
+			s, err = openSorted(filename, opts)
+		default:
+			s, err = spillFile(filename, opts, *tmpDir, *spillSize<<20)
+		}
+		if err != nil {
+			return err
+		}
+		streams = append(streams, s)
+	}
+
+	if canLookup(name, indexes) {
+		return lookupCommand(p, name, streams[0], indexes)
+	}
+	return mergeCommand(p, name, streams, *tmpDir, *spillSize<<20)
+}
 
-	cmd(set1, set2)
+// keySpec returns the options that keys are built with, which are stored
+// in index files.
+func keySpec() string {
+	return fmt.Sprintf("-k=%d -d=%q -re=%q -i=%t -trim=%t -squeeze=%t -nfc=%t",
+		*keyField, *keyDelim, *keyRegex, *ignoreCase, *trim, *squeeze, *nfc)
 }
 
 func usage() {
-	fmt.Fprintf(os.Stderr, "Usage: %s <command> <file1> <file2>\n", filepath.Base(os.Args[0]))
+	fmt.Fprintf(os.Stderr, "Usage: %s [<options>] <command> <file1> <file2> [<file>...]\n", filepath.Base(os.Args[0]))
 	fmt.Fprintln(os.Stderr)
 	fmt.Fprintln(os.Stderr, "where <command> is one of:")
 	fmt.Fprintln(os.Stderr, "\tdiff  - shows unique lines that are different between file1 (-) and file2 (+)")
-	fmt.Fprintln(os.Stderr, "\tminus - shows unique lines in file1 that are not in file2")
-	fmt.Fprintln(os.Stderr, "\tcross - shows unique lines that are common between file1 and file2")
+	fmt.Fprintln(os.Stderr, "\tcomm  - shows unique lines in 3 columns like comm, lines only in file1, lines")
+	fmt.Fprintln(os.Stderr, "\t        only in file2 and lines in both files, suppressed with -1, -2 and -3")
+	fmt.Fprintln(os.Stderr, "\tunion - shows unique lines that are in any of the files")
+	fmt.Fprintln(os.Stderr, "\txor   - shows unique lines that are in only one of file1 and file2, or in an")
+	fmt.Fprintln(os.Stderr, "\t        odd number of files when given more files")
+	fmt.Fprintln(os.Stderr, "\tminus - shows unique lines in file1 that are not in any of the other files")
+	fmt.Fprintln(os.Stderr, "\tcross - shows unique lines that are common to all files")
+	fmt.Fprintln(os.Stderr, "\tcount - shows unique lines of all files prefixed with the number of files")
+	fmt.Fprintln(os.Stderr, "\t        containing the line and the list of those files, numbered from 1")
+	fmt.Fprintln(os.Stderr, "\tsum   - with -bag, shows lines of all files with their total counts")
+	fmt.Fprintln(os.Stderr, "\tstats - shows the number of unique lines of file1 and file2, of their")
+	fmt.Fprintln(os.Stderr, "\t        intersection, union and differences, and the Jaccard and overlap")
+	fmt.Fprintln(os.Stderr, "\t        coefficients")
+	fmt.Fprintln(os.Stderr, "\tindex - writes an index of file1 to file2, which can be given in place of")
+	fmt.Fprintln(os.Stderr, "\t        file1 to other commands")
+	fmt.Fprintln(os.Stderr, "\tsubset, superset, disjoint")
+	fmt.Fprintln(os.Stderr, "\t      - exits with status 0 if file1 is a subset of, a superset of or has no")
+	fmt.Fprintln(os.Stderr, "\t        lines in common with file2, 1 if not and 2 on error, and prints")
+	fmt.Fprintln(os.Stderr, "\t        nothing unless -v is given to print the lines that make it false")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "diff, comm, stats, index and the predicates take exactly 2 files, all other")
+	fmt.Fprintln(os.Stderr, "commands take 2 or more files. If a file is '-', it reads from stdin. Files")
+	fmt.Fprintln(os.Stderr, "ending in .gz, .bz2, .zst or .xz are decompressed. Files may be glob")
+	fmt.Fprintln(os.Stderr, "patterns such as 'logs/*.gz', which are expanded in sorted order.")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Lines end with a newline unless -rs gives another separator, and output")
+	fmt.Fprintln(os.Stderr, "lines end with -ors. -z reads and writes lines ending with NUL, such as the")
+	fmt.Fprintln(os.Stderr, "output of find -print0 and the input of xargs -0.")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "With -k or -re, lines are compared on a key extracted from each line, and")
+	fmt.Fprintln(os.Stderr, "the first line of a file with a given key is printed. Lines without the")
+	fmt.Fprintln(os.Stderr, "key field or not matching the regular expression use the whole line as key.")
+	fmt.Fprintln(os.Stderr, "The -i, -trim, -squeeze and -nfc options normalize keys the same way.")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Output is sorted by key unless -order is given. With -order=fileN, lines")
+	fmt.Fprintln(os.Stderr, "follow the order they first appear in fileN, followed by lines from the other")
+	fmt.Fprintln(os.Stderr, "files in the order of the files.")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "With -bag, duplicated lines are counted and lines are printed prefixed with")
+	fmt.Fprintln(os.Stderr, "their count. union takes the largest count, cross the smallest count, minus")
+	fmt.Fprintln(os.Stderr, "subtracts counts and diff shows the differences of counts.")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "With -spill, files are sorted in chunks written to temporary files, which")
+	fmt.Fprintln(os.Stderr, "are then merged. This supports files larger than memory for all commands")
+	fmt.Fprintln(os.Stderr, "except sum, and does not support -bag or -order.")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "With -sorted, files must already be sorted by key in byte order, such as with")
+	fmt.Fprintln(os.Stderr, "LC_ALL=C sort, and are merged while being read. It has the same restrictions")
+	fmt.Fprintln(os.Stderr, "as -spill and fails on the first line that is out of order.")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "An index holds the unique lines of a file sorted by key, and must be used")
+	fmt.Fprintln(os.Stderr, "with the same key options that it was built with. Commands given an index")
+	fmt.Fprintln(os.Stderr, "have the same restrictions as -spill. minus and cross look up the lines of")
+	fmt.Fprintln(os.Stderr, "file1 if all other files are indexes.")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "With -approx, minus and cross hold a Bloom filter of each file but the first,")
+	fmt.Fprintln(os.Stderr, "which takes at least -1.44*log2(fp-rate) bits per distinct line and about 4")
+	fmt.Fprintln(os.Stderr, "bytes at the default rate as the filter grows. Lines of file1 are printed in")
+	fmt.Fprintln(os.Stderr, "order, including repeated lines, and each line is wrongly found in another")
+	fmt.Fprintln(os.Stderr, "file with a probability of at most -fp-rate. stats uses memory that does not")
+	fmt.Fprintln(os.Stderr, "depend on the size of the files, with a standard error of about 0.8% for the")
+	fmt.Fprintln(os.Stderr, "sizes and the union.")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "With -o json or ndjson, each line is a record with the value and the names")
+	fmt.Fprintln(os.Stderr, "of the files containing it, followed by a summary of the number of results.")
+	fmt.Fprintln(os.Stderr, "With -o csv, each row has the value and a 1 or 0 column for each file.")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Options:")
+	flag.PrintDefaults()
 	fmt.Fprintln(os.Stderr)
 }
 
-func readFile(filename string) (set.Strings, error) {
-	s := set.Strings{}
-	f, err := os.Open(filename)
-	if err != nil {
-		return set.Strings{}, err
+// diff prints the difference between inputs[0] (-) and inputs[1] (+).
+func diff(p printer, inputs []*input) {
+	adds, subs := inputs[0].keys.Diff(inputs[1].keys)
+	for _, key := range orderKeys(inputs, subs) {
+		p.print(result{line: inputs[0].line(key), in: []bool{true, false}})
+	}
+	for _, key := range orderKeys(inputs, adds) {
+		p.print(result{line: inputs[1].line(key), in: []bool{false, true}})
+	}
+}
+
+// comm prints out values of inputs[0] and inputs[1] in 3 columns like comm,
+// except for the columns suppressed by commColumns.
+func comm(p printer, inputs []*input) {
+	for _, key := range orderKeys(inputs, inputs[0].keys.Union(inputs[1].keys)) {
+		in := contains(inputs, key)
+		if commColumns[commColumn(in)] {
+			p.print(result{line: lineOf(inputs, key), in: in})
+		}
+	}
+}
+
+// union prints out values that are in any of the inputs.
+func union(p printer, inputs []*input) {
+	res := inputs[0].keys
+	for _, in := range inputs[1:] {
+		res = res.Union(in.keys)
 	}
-	defer f.Close()
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		s.Add(scanner.Text())
+	printKeys(p, inputs, res)
+}
+
+// xor prints out the symmetric difference of the inputs. With more than 2
+// inputs, these are values that are in an odd number of inputs.
+func xor(p printer, inputs []*input) {
+	res := inputs[0].keys
+	for _, in := range inputs[1:] {
+		res = res.SymmetricDifference(in.keys)
 	}
-	if err := scanner.Err(); err != nil {
-		return set.Strings{}, err
+	printKeys(p, inputs, res)
+}
+
+// subtract prints out values in inputs[0] that are not in any of the other
+// inputs.
+func subtract(p printer, inputs []*input) {
+	res := inputs[0].keys.Minus(inputs[1].keys)
+	for _, in := range inputs[2:] {
+		res.RemoveSet(in.keys)
+	}
+	printKeys(p, inputs, res)
+}
+
+// intersect prints out values that are in all of the inputs.
+func intersect(p printer, inputs []*input) {
+	res := inputs[0].keys
+	for _, in := range inputs[1:] {
+		res = res.Intersect(in.keys)
+	}
+	printKeys(p, inputs, res)
+}
+
+// count prints out values that are in any of the inputs, prefixed with the
+// number of inputs containing the value and the 1-based positions of those
+// inputs.
+func count(p printer, inputs []*input) {
+	all := set.Strings{}
+	for _, in := range inputs {
+		all.AddSet(in.keys)
 	}
-	return s, nil
+	printKeys(p, inputs, all)
 }
 
-// diff prints the difference between s1 (-) and s2 (+).
-func diff(s1, s2 set.Strings) {
-	adds, subs := s1.Diff(s2)
-	for _, val := range subs.SortedSlice() {
-		fmt.Printf("-%s\n", val)
+// printKeys prints out the lines of the given keys in output order.
+func printKeys(p printer, inputs []*input, keys set.Strings) {
+	for _, key := range orderKeys(inputs, keys) {
+		p.print(result{line: lineOf(inputs, key), in: contains(inputs, key)})
 	}
-	for _, val := range adds.SortedSlice() {
-		fmt.Printf("+%s\n", val)
+}
+
+// contains returns which inputs contain the key.
+func contains(inputs []*input, key string) []bool {
+	in := make([]bool, len(inputs))
+	for i, input := range inputs {
+		in[i] = input.keys.Contains(key)
+	}
+	return in
+}
+
+// orderKeys returns the given keys in output order.
+func orderKeys(inputs []*input, keys set.Strings) []string {
+	switch outputOrder {
+	case orderSorted:
+		return keys.SortedSlice(strings.Compare)
+	case orderNone:
+		return keys.Slice()
+	}
+	ordered := set.NewOrderedStrings()
+	add := func(in *input) {
+		for _, key := range in.order {
+			if keys.Contains(key) {
+				ordered.Add(key)
+			}
+		}
+	}
+	add(inputs[outputOrder])
+	for i, in := range inputs {
+		if i != outputOrder {
+			add(in)
+		}
+	}
+	return ordered.Slice()
+}
+
+// subset checks that inputs[0] is a subset of inputs[1]. With -v, it prints
+// out values of inputs[0] that are not in inputs[1].
+func subset(p printer, inputs []*input) {
+	a, b := inputs[0].keys, inputs[1].keys
+	if holds = a.IsSubset(b); !holds && *verbose {
+		printKeys(p, inputs, a.Minus(b))
+	}
+}
+
+// superset checks that inputs[0] is a superset of inputs[1]. With -v, it
+// prints out values of inputs[1] that are not in inputs[0].
+func superset(p printer, inputs []*input) {
+	a, b := inputs[0].keys, inputs[1].keys
+	if holds = a.IsSuperset(b); !holds && *verbose {
+		printKeys(p, inputs, b.Minus(a))
+	}
+}
+
+// disjoint checks that inputs[0] and inputs[1] have no values in common. With
+// -v, it prints out the values in common.
+func disjoint(p printer, inputs []*input) {
+	a, b := inputs[0].keys, inputs[1].keys
+	if holds = a.IsDisjoint(b); !holds && *verbose {
+		printKeys(p, inputs, a.Intersect(b))
+	}
+}
+
+// bagDiff prints the difference of counts between inputs[0] (-) and inputs[1]
+// (+).
+func bagDiff(p printer, inputs []*input) {
+	subs := inputs[0].counts.Minus(inputs[1].counts)
+	adds := inputs[1].counts.Minus(inputs[0].counts)
+	for _, key := range orderKeys(inputs, subs.Strings()) {
+		p.print(result{line: inputs[0].line(key), in: []bool{true, false}, count: subs.Count(key)})
+	}
+	for _, key := range orderKeys(inputs, adds.Strings()) {
+		p.print(result{line: inputs[1].line(key), in: []bool{false, true}, count: adds.Count(key)})
+	}
+}
+
+// bagUnion prints out values with the largest of their counts in the inputs.
+func bagUnion(p printer, inputs []*input) {
+	res := inputs[0].counts
+	for _, in := range inputs[1:] {
+		res = res.Union(in.counts)
+	}
+	printCounts(p, inputs, res)
+}
+
+// bagSubtract prints out values of inputs[0] with their counts reduced by
+// their counts in the other inputs.
+func bagSubtract(p printer, inputs []*input) {
+	res := inputs[0].counts
+	for _, in := range inputs[1:] {
+		res = res.Minus(in.counts)
+	}
+	printCounts(p, inputs, res)
+}
+
+// bagIntersect prints out values with the smallest of their counts in the
+// inputs.
+func bagIntersect(p printer, inputs []*input) {
+	res := inputs[0].counts
+	for _, in := range inputs[1:] {
+		res = res.Intersect(in.counts)
 	}
+	printCounts(p, inputs, res)
 }
 
-// subtract prints out values in s1 that are not in s2.
-func subtract(s1, s2 set.Strings) {
-	res := s1.Minus(s2)
-	for _, val := range res.SortedSlice() {
-		fmt.Println(val)
+// sum prints out values with the sum of their counts in the inputs.
+func sum(p printer, inputs []*input) {
+	res := inputs[0].counts
+	for _, in := range inputs[1:] {
+		res = res.Sum(in.counts)
 	}
+	printCounts(p, inputs, res)
 }
 
-// intersect prints out values that are in both s1 and s2.
-func intersect(s1, s2 set.Strings) {
-	for _, val := range s1.Intersect(s2).SortedSlice() {
-		fmt.Println(val)
+// printCounts prints out the lines of the given counts in output order with
+// their counts.
+func printCounts(p printer, inputs []*input, counts set.Counts) {
+	for _, key := range orderKeys(inputs, counts.Strings()) {
+		p.print(result{line: lineOf(inputs, key), in: contains(inputs, key), count: counts.Count(key)})
 	}
 }
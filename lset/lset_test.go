@@ -0,0 +1,752 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/cybrcodr/txttools/lset/internal/set"
+	"github.com/google/go-cmp/cmp"
+	"github.com/klauspost/compress/zstd"
+)
+
+var memoryCommands = map[string]func(printer, []*input){
+	"diff":  diff,
+	"comm":  comm,
+	"union": union,
+	"xor":   xor,
+	"cross": intersect,
+	"minus": subtract,
+	"count": count,
+	"stats": stats,
+}
+
+// writeRandomFiles writes n files of random lines into dir. Lines are built
+// from few distinct words, with varying case and spacing, so that files
+// overlap and keys collide.
+func writeRandomFiles(t *testing.T, dir string, n int) []string {
+	t.Helper()
+	rnd := rand.New(rand.NewSource(int64(n)))
+	words := []string{"alpha", "Beta", "gamma", "DELTA", "eps"}
+	var filenames []string
+	for i := 0; i < n; i++ {
+		var buf bytes.Buffer
+		for j := rnd.Intn(300); j > 0; j-- {
+			fmt.Fprintf(&buf, "%s%s%d\n", words[rnd.Intn(len(words))], strings.Repeat(" ", rnd.Intn(3)), rnd.Intn(40))
+		}
+		filename := filepath.Join(dir, fmt.Sprintf("file%d", i+1))
+		if err := os.WriteFile(filename, buf.Bytes(), 0644); err != nil {
+			t.Fatal(err)
+		}
+		filenames = append(filenames, filename)
+	}
+	return filenames
+}
+
+// writeFiles writes files with the given contents into dir, named file1,
+// file2 and so on.
+func writeFiles(t *testing.T, dir string, contents ...string) []string {
+	t.Helper()
+	var filenames []string
+	for i, data := range contents {
+		filename := filepath.Join(dir, fmt.Sprintf("file%d", i+1))
+		if err := os.WriteFile(filename, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+		filenames = append(filenames, filename)
+	}
+	return filenames
+}
+
+func memoryOutput(t *testing.T, name string, filenames []string, opts readOptions) string {
+	t.Helper()
+	var inputs []*input
+	for _, filename := range filenames {
+		in, err := readFile(filename, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		inputs = append(inputs, in)
+	}
+	var buf bytes.Buffer
+	p := &textPrinter{w: &buf, cmd: name}
+	memoryCommands[name](p, inputs)
+	return buf.String()
+}
+
+func mergeOutput(t *testing.T, name string, streams []stream, dir string) string {
+	t.Helper()
+	defer func() {
+		for _, s := range streams {
+			s.Close()
+		}
+	}()
+	var buf bytes.Buffer
+	p := &textPrinter{w: &buf, cmd: name}
+	// Use a small limit so that diff keeps lines in temporary files.
+	if err := mergeCommand(p, name, streams, dir, 64); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+// keyOptions are the key options that the different modes are tested with.
+var keyOptions = []struct {
+	desc string
+	opts readOptions
+}{
+	{
+		desc: "lines",
+		opts: readOptions{},
+	},
+	{
+		desc: "field",
+		opts: readOptions{key: fieldKey(1, "")},
+	},
+	{
+		desc: "normalized",
+		opts: readOptions{key: normalizeKey(nil, normalizers(false, true, false, true))},
+	},
+}
+
+func TestSpillMatchesMemory(t *testing.T) {
+	dir := t.TempDir()
+	filenames := writeRandomFiles(t, dir, 3)
+	tmpDir := filepath.Join(dir, "tmp")
+	if err := os.Mkdir(tmpDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, ko := range keyOptions {
+		for name := range memoryCommands {
+			files := filenames
+			if name == "diff" || name == "comm" || name == "stats" {
+				files = filenames[:2]
+			}
+			want := memoryOutput(t, name, files, ko.opts)
+
+			// Use a small limit so that files are sorted in many chunks.
+			var streams []stream
+			for _, filename := range files {
+				s, err := spillFile(filename, ko.opts, tmpDir, 64)
+				if err != nil {
+					t.Fatal(err)
+				}
+				streams = append(streams, s)
+			}
+			got := mergeOutput(t, name, streams, tmpDir)
+			if diff := cmp.Diff(got, want); diff != "" {
+				t.Errorf("%s %s: diff %s", ko.desc, name, diff)
+			}
+		}
+	}
+
+	left, err := os.ReadDir(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(left) != 0 {
+		t.Errorf("%d temporary files left", len(left))
+	}
+}
+
+func TestSortedMatchesMemory(t *testing.T) {
+	dir := t.TempDir()
+	filenames := writeRandomFiles(t, dir, 3)
+
+	for _, ko := range keyOptions {
+		// Sort the files by key, keeping the order of lines with the same key.
+		var sortedNames []string
+		for _, filename := range filenames {
+			s, err := spillFile(filename, ko.opts, dir, 1<<20)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var buf bytes.Buffer
+			for s.(*spillStream).it.Next() {
+				rec := s.(*spillStream).it.Record()
+				line := rec.Key
+				if ko.opts.key != nil {
+					line = rec.Value
+				}
+				fmt.Fprintln(&buf, line)
+			}
+			s.Close()
+			sortedName := filename + "." + ko.desc
+			if err := os.WriteFile(sortedName, buf.Bytes(), 0644); err != nil {
+				t.Fatal(err)
+			}
+			sortedNames = append(sortedNames, sortedName)
+		}
+
+		for name := range memoryCommands {
+			files := sortedNames
+			if name == "diff" || name == "comm" || name == "stats" {
+				files = sortedNames[:2]
+			}
+			want := memoryOutput(t, name, files, ko.opts)
+
+			var streams []stream
+			for _, filename := range files {
+				s, err := openSorted(filename, ko.opts)
+				if err != nil {
+					t.Fatal(err)
+				}
+				streams = append(streams, s)
+			}
+			got := mergeOutput(t, name, streams, dir)
+			if diff := cmp.Diff(got, want); diff != "" {
+				t.Errorf("%s %s: diff %s", ko.desc, name, diff)
+			}
+		}
+	}
+}
+
+func TestSortedOutOfOrder(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "unsorted")
+	if err := os.WriteFile(filename, []byte("a\nb\nb\nc\nb\nd\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := openSorted(filename, readOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	var buf bytes.Buffer
+	err = mergeCommand(&textPrinter{w: &buf, cmd: "union"}, "union", []stream{s}, dir, 1<<20)
+	want := filename + ":5: input is not sorted"
+	if err == nil || err.Error() != want {
+		t.Errorf("got error %v, want %q", err, want)
+	}
+}
+
+func TestNewSetStats(t *testing.T) {
+	tests := []struct {
+		desc               string
+		size1, size2, both int
+		want               setStats
+	}{
+		{
+			desc:  "overlapping",
+			size1: 3, size2: 2, both: 1,
+			want: setStats{sizes: [2]int{3, 2}, cross: 1, union: 4, only: [2]int{2, 1}, jaccard: 0.25, overlap: 0.5},
+		},
+		{
+			desc:  "subset",
+			size1: 2, size2: 4, both: 2,
+			want: setStats{sizes: [2]int{2, 4}, cross: 2, union: 4, only: [2]int{0, 2}, jaccard: 0.5, overlap: 1},
+		},
+		{
+			desc:  "disjoint",
+			size1: 1, size2: 1, both: 0,
+			want: setStats{sizes: [2]int{1, 1}, cross: 0, union: 2, only: [2]int{1, 1}, jaccard: 0, overlap: 0},
+		},
+		{
+			desc:  "one empty",
+			size1: 0, size2: 2, both: 0,
+			want: setStats{sizes: [2]int{0, 2}, cross: 0, union: 2, only: [2]int{0, 2}, jaccard: 0, overlap: 1},
+		},
+		{
+			desc: "both empty",
+			want: setStats{jaccard: 1, overlap: 1},
+		},
+	}
+
+	for _, test := range tests {
+		if got := newSetStats(test.size1, test.size2, test.both); got != test.want {
+			t.Errorf("%s: got %+v, want %+v", test.desc, got, test.want)
+		}
+	}
+}
+
+func TestApproxMatchesExact(t *testing.T) {
+	dir := t.TempDir()
+	filenames := writeRandomFiles(t, dir, 3)
+
+	for _, name := range []string{"minus", "cross"} {
+		want := memoryOutput(t, name, filenames, readOptions{})
+
+		var buf bytes.Buffer
+		p := &textPrinter{w: &buf, cmd: name}
+		// With so few lines, a false positive is unlikely at this rate.
+		if err := approxCommand(p, name, filenames, readOptions{}, 1e-6); err != nil {
+			t.Fatal(err)
+		}
+		// Lines are printed in file order with repeats.
+		lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+		got := ""
+		for _, line := range set.NewStrings(lines...).SortedSlice(strings.Compare) {
+			if line != "" {
+				got += line + "\n"
+			}
+		}
+		if diff := cmp.Diff(got, want); diff != "" {
+			t.Errorf("%s: diff %s", name, diff)
+		}
+	}
+
+	files := filenames[:2]
+	in1, err := readFile(files[0], readOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	in2, err := readFile(files[1], readOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := newSetStats(len(in1.keys), len(in2.keys), len(in1.keys.Intersect(in2.keys)))
+	var got setStats
+	p := &statsRecorder{stats: &got}
+	if err := approxCommand(p, "stats", files, readOptions{}, 0.01); err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(got.jaccard-want.jaccard) > 0.05 || math.Abs(got.overlap-want.overlap) > 0.05 {
+		t.Errorf("got stats %+v, want close to %+v", got, want)
+	}
+}
+
+// statsRecorder is a printer that records the statistics.
+type statsRecorder struct {
+	printer
+	stats *setStats
+}
+
+func (p *statsRecorder) printStats(s setStats) {
+	*p.stats = s
+}
+
+func TestIndexMatchesMemory(t *testing.T) {
+	dir := t.TempDir()
+	filenames := writeRandomFiles(t, dir, 3)
+
+	for _, ko := range keyOptions {
+		var indexNames []string
+		for _, filename := range filenames {
+			indexName := filename + "." + ko.desc + ".idx"
+			if err := buildIndex(filename, indexName, ko.opts, ko.desc, dir, 64); err != nil {
+				t.Fatal(err)
+			}
+			indexNames = append(indexNames, indexName)
+		}
+
+		for name := range memoryCommands {
+			files := filenames
+			if name == "diff" || name == "comm" || name == "stats" {
+				files = filenames[:2]
+			}
+			want := memoryOutput(t, name, files, ko.opts)
+
+			// Either all files are indexes or only the first one is not,
+			// which is looked up in the others for minus and cross.
+			for _, first := range []string{indexNames[0], files[0]} {
+				names := append([]string{first}, indexNames[1:len(files)]...)
+				indexes, err := openIndexes(names, ko.desc)
+				if err != nil {
+					t.Fatal(err)
+				}
+				var streams []stream
+				for i, x := range indexes {
+					if x != nil {
+						streams = append(streams, &indexStream{x: x, it: x.Iter()})
+						continue
+					}
+					s, err := spillFile(names[i], ko.opts, dir, 1<<20)
+					if err != nil {
+						t.Fatal(err)
+					}
+					streams = append(streams, s)
+				}
+				var got string
+				if canLookup(name, indexes) {
+					var buf bytes.Buffer
+					if err := lookupCommand(&textPrinter{w: &buf, cmd: name}, name, streams[0], indexes); err != nil {
+						t.Fatal(err)
+					}
+					streams[0].Close()
+					got = buf.String()
+				} else {
+					got = mergeOutput(t, name, streams, dir)
+				}
+				closeIndexes(indexes)
+				if diff := cmp.Diff(got, want); diff != "" {
+					t.Errorf("%s %s with %s: diff %s", ko.desc, name, first, diff)
+				}
+			}
+		}
+
+		if _, err := openIndexes(indexNames[:1], "other options"); err == nil {
+			t.Errorf("%s: openIndexes with other key options succeeded", ko.desc)
+		}
+	}
+}
+
+// TestReadFiles reads files of several chunks concurrently, and is meant to be
+// run with the race detector, as in go test -race.
+func TestReadFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	// Keys repeat with a period that is not a multiple of the chunk size, so
+	// that the first line of a key is in an earlier chunk than its repeats.
+	const period = chunkLines + 7
+	var filenames []string
+	for f := 0; f < 3; f++ {
+		var buf bytes.Buffer
+		for i := 0; i < 3*chunkLines; i++ {
+			fmt.Fprintf(&buf, "key%d line%d\n", (i+f)%period, i)
+		}
+		filename := filepath.Join(dir, fmt.Sprintf("file%d", f+1))
+		if err := os.WriteFile(filename, buf.Bytes(), 0644); err != nil {
+			t.Fatal(err)
+		}
+		filenames = append(filenames, filename)
+	}
+
+	// Use several workers per file even on a single CPU.
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+	opts := readOptions{key: fieldKey(1, ""), trackOrder: true, bag: true}
+	inputs, err := readFiles(filenames, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for f, in := range inputs {
+		if len(in.keys) != period || len(in.order) != period {
+			t.Errorf("file%d: got %d keys and %d ordered keys, want %d", f+1, len(in.keys), len(in.order), period)
+		}
+		for i := 0; i < period; i++ {
+			key := fmt.Sprintf("key%d", (i+f)%period)
+			if in.order[i] != key {
+				t.Errorf("file%d: order[%d] = %q, want %q", f+1, i, in.order[i], key)
+				break
+			}
+			if got, want := in.line(key), fmt.Sprintf("%s line%d", key, i); got != want {
+				t.Errorf("file%d: line(%q) = %q, want %q", f+1, key, got, want)
+			}
+			want := 2
+			if i < 3*chunkLines-2*period {
+				want = 3
+			}
+			if got := in.counts.Count(key); got != want {
+				t.Errorf("file%d: count of %q = %d, want %d", f+1, key, got, want)
+			}
+		}
+	}
+
+	if _, err := readFiles([]string{filenames[0], filepath.Join(dir, "missing")}, opts); err == nil {
+		t.Error("readFiles with a missing file succeeded")
+	}
+}
+
+// TestReadFilesFold reads a file of several chunks with case folded keys,
+// which are computed by concurrent workers, and is meant to be run with the
+// race detector, as in go test -race.
+func TestReadFilesFold(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "file")
+	var buf bytes.Buffer
+	words := []string{"Straße", "STRASSE", "strasse", "ΣΑΣ", "σας", "Key"}
+	for i := 0; i < 4*chunkLines; i++ {
+		fmt.Fprintf(&buf, "%s%d\n", words[i%len(words)], i%97)
+	}
+	if err := os.WriteFile(filename, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+	opts := readOptions{key: normalizeKey(nil, normalizers(false, false, false, true)), bag: true}
+	inputs, err := readFiles([]string{filename, filename}, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, in := range inputs {
+		// The 6 words fold to 3 keys, strasse, σασ and key.
+		if got, want := len(in.keys), 3*97; got != want {
+			t.Errorf("got %d keys, want %d", got, want)
+		}
+		total := 0
+		for k := range in.keys {
+			if !strings.HasPrefix(k, "strasse") && !strings.HasPrefix(k, "σασ") && !strings.HasPrefix(k, "key") {
+				t.Errorf("key %q is not folded", k)
+			}
+			total += in.counts.Count(k)
+		}
+		if total != 4*chunkLines {
+			t.Errorf("got %d line items, want %d", total, 4*chunkLines)
+		}
+	}
+}
+
+func TestPredicates(t *testing.T) {
+	defer func(v bool) { *verbose = v }(*verbose)
+	*verbose = true
+	defer func() { holds = true }()
+
+	newInput := func(lines ...string) *input {
+		return &input{keys: set.NewStrings(lines...)}
+	}
+	for _, test := range []struct {
+		name      string
+		a, b      *input
+		wantHolds bool
+		want      string
+	}{
+		{name: "subset", a: newInput("a"), b: newInput("a", "b"), wantHolds: true},
+		{name: "subset", a: newInput("a", "c", "d"), b: newInput("a", "b"), want: "c\nd\n"},
+		{name: "superset", a: newInput("a", "b"), b: newInput("b"), wantHolds: true},
+		{name: "superset", a: newInput("a"), b: newInput("a", "b"), want: "b\n"},
+		{name: "disjoint", a: newInput("a"), b: newInput("b"), wantHolds: true},
+		{name: "disjoint", a: newInput(), b: newInput(), wantHolds: true},
+		{name: "disjoint", a: newInput("a", "b", "c"), b: newInput("c", "b"), want: "b\nc\n"},
+	} {
+		cmd := map[string]func(printer, []*input){
+			"subset":   subset,
+			"superset": superset,
+			"disjoint": disjoint,
+		}[test.name]
+		var buf bytes.Buffer
+		cmd(&textPrinter{w: &buf, cmd: test.name}, []*input{test.a, test.b})
+		if holds != test.wantHolds {
+			t.Errorf("%s %v %v: got %t, want %t", test.name, test.a.keys, test.b.keys, holds, test.wantHolds)
+		}
+		if got := buf.String(); got != test.want {
+			t.Errorf("%s %v %v: printed %q, want %q", test.name, test.a.keys, test.b.keys, got, test.want)
+		}
+	}
+}
+
+// TestPredicateStatus runs main in a child process to check the exit status
+// of predicates, which is 0 if they hold, 1 if not and 2 on errors.
+func TestPredicateStatus(t *testing.T) {
+	if args := os.Getenv("LSET_TEST_ARGS"); args != "" {
+		os.Args = append([]string{"lset"}, strings.Fields(args)...)
+		main()
+		// Exit before the test framework prints to stdout.
+		os.Exit(0)
+	}
+	dir := t.TempDir()
+	filenames := writeFiles(t, dir, "a\nb\n", "a\nb\nc\n", "c\nd\n")
+	ab, abc, cd := filenames[0], filenames[1], filenames[2]
+	missing := filepath.Join(dir, "missing")
+	for _, conf := range []struct {
+		args       string
+		wantStatus int
+		want       string
+	}{
+		{args: "subset " + ab + " " + abc},
+		{args: "subset " + abc + " " + ab, wantStatus: 1},
+		{args: "-v subset " + abc + " " + ab, wantStatus: 1, want: "c\n"},
+		{args: "superset " + abc + " " + ab},
+		{args: "-v superset " + ab + " " + cd, wantStatus: 1, want: "c\nd\n"},
+		{args: "disjoint " + ab + " " + cd},
+		{args: "-v disjoint " + abc + " " + cd, wantStatus: 1, want: "c\n"},
+		{args: "subset " + ab + " " + missing, wantStatus: 2},
+		{args: "disjoint " + ab, wantStatus: 2},
+		{args: "-bag superset " + ab + " " + abc, wantStatus: 2},
+	} {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestPredicateStatus$")
+		cmd.Env = append(os.Environ(), "LSET_TEST_ARGS="+conf.args)
+		var stdout bytes.Buffer
+		cmd.Stdout = &stdout
+		err := cmd.Run()
+		status := 0
+		if e, ok := err.(*exec.ExitError); ok {
+			status = e.ExitCode()
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		if status != conf.wantStatus {
+			t.Errorf("lset %s: got exit status %d, want %d", conf.args, status, conf.wantStatus)
+		}
+		if conf.wantStatus == 2 {
+			continue
+		}
+		if got := stdout.String(); got != conf.want {
+			t.Errorf("lset %s: printed %q, want %q", conf.args, got, conf.want)
+		}
+	}
+}
+
+func TestOrderKeys(t *testing.T) {
+	defer func(o int) { outputOrder = o }(outputOrder)
+
+	newInput := func(order ...string) *input {
+		return &input{keys: set.NewStrings(order...), order: order}
+	}
+	inputs := []*input{
+		newInput("c", "a", "x"),
+		newInput("b", "a", "d"),
+		newInput("e", "c"),
+	}
+	keys := set.NewStrings("a", "b", "c", "d", "e")
+	for _, test := range []struct {
+		order int
+		want  []string
+	}{
+		{order: orderSorted, want: []string{"a", "b", "c", "d", "e"}},
+		{order: 0, want: []string{"c", "a", "b", "d", "e"}},
+		{order: 1, want: []string{"b", "a", "d", "c", "e"}},
+		{order: 2, want: []string{"e", "c", "a", "b", "d"}},
+	} {
+		outputOrder = test.order
+		if diff := cmp.Diff(test.want, orderKeys(inputs, keys)); diff != "" {
+			t.Errorf("order %d: -want +got\n%s", test.order, diff)
+		}
+	}
+
+	// Without order, all keys are returned once in any order.
+	outputOrder = orderNone
+	got := set.NewStrings(orderKeys(inputs, keys)...)
+	if len(got) != len(keys) || !got.IsSubset(keys) {
+		t.Errorf("order none: got %v, want %v", got, keys)
+	}
+	if n := len(orderKeys(inputs, keys)); n != len(keys) {
+		t.Errorf("order none: got %d keys, want %d", n, len(keys))
+	}
+}
+
+func TestCommColumns(t *testing.T) {
+	defer func(c [3]bool) { commColumns = c }(commColumns)
+	dir := t.TempDir()
+	filenames := writeFiles(t, dir, "a\nc\nd\n", "b\nc\nd\ne\n")
+
+	for _, test := range []struct {
+		hide string
+		want string
+	}{
+		{hide: "", want: "a\n\tb\n\t\tc\n\t\td\n\te\n"},
+		{hide: "1", want: "b\n\tc\n\td\ne\n"},
+		{hide: "2", want: "a\n\tc\n\td\n"},
+		{hide: "3", want: "a\n\tb\n\te\n"},
+		{hide: "12", want: "c\nd\n"},
+		{hide: "13", want: "b\ne\n"},
+		{hide: "23", want: "a\n"},
+		{hide: "123", want: ""},
+	} {
+		commColumns = [3]bool{
+			!strings.Contains(test.hide, "1"),
+			!strings.Contains(test.hide, "2"),
+			!strings.Contains(test.hide, "3"),
+		}
+		if got := memoryOutput(t, "comm", filenames, readOptions{}); got != test.want {
+			t.Errorf("-%s comm = %q, want %q", test.hide, got, test.want)
+		}
+
+		var streams []stream
+		for _, filename := range filenames {
+			s, err := openSorted(filename, readOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			streams = append(streams, s)
+		}
+		if got := mergeOutput(t, "comm", streams, dir); got != test.want {
+			t.Errorf("-%s -sorted comm = %q, want %q", test.hide, got, test.want)
+		}
+	}
+}
+
+func TestCountOutput(t *testing.T) {
+	dir := t.TempDir()
+	filenames := writeFiles(t, dir, "db01\nweb12\n", "db01\nweb07\n", "db01\nweb12\nweb12\n")
+	want := "3\t1,2,3\tdb01\n1\t2\tweb07\n2\t1,3\tweb12\n"
+	if got := memoryOutput(t, "count", filenames, readOptions{}); got != want {
+		t.Errorf("count = %q, want %q", got, want)
+	}
+
+	var streams []stream
+	for _, filename := range filenames {
+		s, err := openSorted(filename, readOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		streams = append(streams, s)
+	}
+	if got := mergeOutput(t, "count", streams, dir); got != want {
+		t.Errorf("-sorted count = %q, want %q", got, want)
+	}
+}
+
+// TestReadInputs reads compressed files and stdin through the loader.
+func TestReadInputs(t *testing.T) {
+	dir := t.TempDir()
+	var gz, zst bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	zw.Write([]byte("b\na\nb\n"))
+	zw.Close()
+	enc, err := zstd.NewWriter(&zst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc.Write([]byte("c\nb\n"))
+	enc.Close()
+	for name, data := range map[string][]byte{
+		"file1.gz":  gz.Bytes(),
+		"file2.zst": zst.Bytes(),
+		"stdin":     []byte("d\r\nc\r\n"),
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	stdin, err := os.Open(filepath.Join(dir, "stdin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stdin.Close()
+	defer func(f *os.File) { os.Stdin = f }(os.Stdin)
+	os.Stdin = stdin
+
+	filenames := []string{filepath.Join(dir, "file1.gz"), filepath.Join(dir, "file2.zst"), "-"}
+	want := "1\t1\ta\n2\t1,2\tb\n2\t2,3\tc\n1\t3\td\n"
+	if got := memoryOutput(t, "count", filenames, readOptions{}); got != want {
+		t.Errorf("count = %q, want %q", got, want)
+	}
+}
+
+func TestBagCommands(t *testing.T) {
+	dir := t.TempDir()
+	filenames := writeFiles(t, dir, "a\nb\na\nc\na\n", "b\na\nd\nb\n")
+	inputs, err := readFiles(filenames, readOptions{bag: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		name string
+		cmd  func(printer, []*input)
+		want string
+	}{
+		{name: "diff", cmd: bagDiff, want: "-2\ta\n-1\tc\n+1\tb\n+1\td\n"},
+		{name: "union", cmd: bagUnion, want: "3\ta\n2\tb\n1\tc\n1\td\n"},
+		{name: "minus", cmd: bagSubtract, want: "2\ta\n1\tc\n"},
+		{name: "cross", cmd: bagIntersect, want: "1\ta\n1\tb\n"},
+		{name: "sum", cmd: sum, want: "4\ta\n3\tb\n1\tc\n1\td\n"},
+	} {
+		var buf bytes.Buffer
+		test.cmd(&textPrinter{w: &buf, cmd: test.name, bag: true}, inputs)
+		if got := buf.String(); got != test.want {
+			t.Errorf("-bag %s = %q, want %q", test.name, got, test.want)
+		}
+	}
+}
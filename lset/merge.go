@@ -0,0 +1,253 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/cybrcodr/txttools/internal/infile"
+	"github.com/cybrcodr/txttools/internal/lines"
+	"github.com/cybrcodr/txttools/lset/internal/extsort"
+)
+
+// stream is a sorted stream of the distinct keys of an input. Set operations
+// on streams are done by merging them, which only needs to hold the current
+// key of each stream in memory.
+type stream interface {
+	// Next advances the stream to the next key. It returns false when there
+	// are no more keys or on error.
+	Next() bool
+	// Key returns the current key.
+	Key() string
+	// Line returns the first line item with the current key.
+	Line() string
+	// Err returns the error that stopped the stream, if any.
+	Err() error
+	// Close releases resources held by the stream.
+	Close() error
+}
+
+// mergeCommands are the commands that can be done by merging streams.
+var mergeCommands = map[string]bool{
+	"diff":  true,
+	"comm":  true,
+	"union": true,
+	"xor":   true,
+	"cross": true,
+	"minus": true,
+	"count": true,
+	"stats": true,
+}
+
+// merge walks the streams in key order and calls fn for each distinct key with
+// which streams contain the key and the line of the first such stream.
+func merge(streams []stream, fn func(line string, in []bool) error) error {
+	ok := make([]bool, len(streams))
+	for i, s := range streams {
+		if ok[i] = s.Next(); !ok[i] && s.Err() != nil {
+			return s.Err()
+		}
+	}
+	in := make([]bool, len(streams))
+	for {
+		first := -1
+		for i, s := range streams {
+			if ok[i] && (first < 0 || s.Key() < streams[first].Key()) {
+				first = i
+			}
+		}
+		if first < 0 {
+			break
+		}
+		key := streams[first].Key()
+		for i, s := range streams {
+			in[i] = ok[i] && s.Key() == key
+		}
+		if err := fn(streams[first].Line(), in); err != nil {
+			return err
+		}
+		for i, s := range streams {
+			if in[i] {
+				if ok[i] = s.Next(); !ok[i] && s.Err() != nil {
+					return s.Err()
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// mergeCommand runs the named command by merging the streams. The output is
+// the same as the command on inputs with sorted output. Temporary files are
+// created in dir if diff needs to hold more than limit bytes.
+func mergeCommand(p printer, name string, streams []stream, dir string, limit int) error {
+	switch name {
+	case "diff":
+		return mergeDiff(p, streams, dir, limit)
+	case "stats":
+		return mergeStats(p, streams)
+	}
+	return merge(streams, func(line string, in []bool) error {
+		n := 0
+		for _, ok := range in {
+			if ok {
+				n++
+			}
+		}
+		var keep bool
+		switch name {
+		case "union", "count":
+			keep = true
+		case "comm":
+			keep = commColumns[commColumn(in)]
+		case "xor":
+			keep = n%2 == 1
+		case "cross":
+			keep = n == len(in)
+		case "minus":
+			keep = in[0] && n == 1
+		}
+		if keep {
+			p.print(result{line: line, in: append([]bool(nil), in...)})
+		}
+		return nil
+	})
+}
+
+// mergeStats prints the statistics of streams[0] and streams[1].
+func mergeStats(p printer, streams []stream) error {
+	var size1, size2, cross int
+	err := merge(streams, func(line string, in []bool) error {
+		if in[0] {
+			size1++
+		}
+		if in[1] {
+			size2++
+		}
+		if in[0] && in[1] {
+			cross++
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	p.printStats(newSetStats(size1, size2, cross))
+	return nil
+}
+
+// mergeDiff prints the difference between streams[0] (-) and streams[1] (+).
+// As all removed lines are printed before added lines, added lines are kept
+// until the merge is done, in temporary files if needed.
+func mergeDiff(p printer, streams []stream, dir string, limit int) error {
+	adds := extsort.New(dir, limit)
+	defer adds.Close()
+	err := merge(streams, func(line string, in []bool) error {
+		switch {
+		case in[0] && !in[1]:
+			p.print(result{line: line, in: []bool{true, false}})
+		case in[1] && !in[0]:
+			// Lines are merged in order, hence all keys are the same
+			// to keep them in the order they are added.
+			return adds.Add("", line)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	it, err := adds.Sort()
+	if err != nil {
+		return err
+	}
+	defer it.Close()
+	for it.Next() {
+		p.print(result{line: it.Record().Value, in: []bool{false, true}})
+	}
+	return it.Err()
+}
+
+// sortedStream is a stream over a file that is already sorted by key.
+type sortedStream struct {
+	filename string
+	f        io.ReadCloser
+	r        *lines.Reader
+	keyFn    keyFunc
+
+	started   bool
+	key, line string
+	err       error
+}
+
+// openSorted returns a stream over the named file that is expected to be
+// sorted by key. Reading the stream fails on the first key that is out of
+// order.
+func openSorted(filename string, opts readOptions) (stream, error) {
+	f, err := infile.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	return &sortedStream{
+		filename: filename,
+		f:        f,
+		r:        lines.NewRecordReader(f, filename, opts.maxLine, opts.sep),
+		keyFn:    opts.key,
+	}, nil
+}
+
+func (s *sortedStream) Next() bool {
+	if s.err != nil {
+		return false
+	}
+	for s.r.Scan() {
+		line := s.r.Text()
+		key := line
+		if s.keyFn != nil {
+			key = s.keyFn(line)
+		}
+		if s.started {
+			if key == s.key {
+				continue
+			}
+			if key < s.key {
+				s.err = fmt.Errorf("%s:%d: input is not sorted", s.filename, s.r.Line())
+				return false
+			}
+		}
+		s.started = true
+		s.key, s.line = key, line
+		return true
+	}
+	s.err = s.r.Err()
+	return false
+}
+
+func (s *sortedStream) Key() string {
+	return s.key
+}
+
+func (s *sortedStream) Line() string {
+	return s.line
+}
+
+func (s *sortedStream) Err() error {
+	return s.err
+}
+
+func (s *sortedStream) Close() error {
+	return s.f.Close()
+}
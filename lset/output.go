@@ -0,0 +1,308 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// result is a line of the output of a command.
+type result struct {
+	line string
+	// in tells which inputs contain the line. For diff, only the input that
+	// the line is removed from or added to is set.
+	in []bool
+	// count is the number of occurrences of the line for -bag.
+	count int
+}
+
+// printer prints the results of a command.
+type printer interface {
+	print(r result)
+	// printStats prints the statistics of the stats command instead of
+	// results.
+	printStats(s setStats)
+	// close prints anything that follows the results.
+	close() error
+}
+
+// newPrinter returns a printer for the given output format. The names of the
+// inputs are used in structured formats.
+func newPrinter(w io.Writer, format, cmd string, names []string, bag bool) (printer, error) {
+	switch format {
+	case "text":
+		return &textPrinter{w: w, cmd: cmd, bag: bag}, nil
+	case "json", "ndjson":
+		return &jsonPrinter{w: w, names: names, bag: bag, nd: format == "ndjson", counts: make([]int, len(names))}, nil
+	case "csv":
+		p := &csvPrinter{w: csv.NewWriter(w), bag: bag}
+		header := append([]string{"value"}, names...)
+		switch {
+		case cmd == "stats":
+			header = []string{"stat", "value"}
+		case bag:
+			header = append(header, "count")
+		}
+		p.w.Write(header)
+		return p, nil
+	}
+	return nil, fmt.Errorf("unknown format %q", format)
+}
+
+// textPrinter prints results as lines ending with lineEnd.
+type textPrinter struct {
+	w   io.Writer
+	cmd string
+	bag bool
+}
+
+func (p *textPrinter) print(r result) {
+	switch p.cmd {
+	case "count":
+		printCount(p.w, r.in, r.line)
+	case "comm":
+		printComm(p.w, r.in, r.line)
+	case "diff":
+		sign := "+"
+		if r.in[0] {
+			sign = "-"
+		}
+		if p.bag {
+			fmt.Fprintf(p.w, "%s%d\t%s%s", sign, r.count, r.line, lineEnd)
+		} else {
+			fmt.Fprintf(p.w, "%s%s%s", sign, r.line, lineEnd)
+		}
+	default:
+		if p.bag {
+			fmt.Fprintf(p.w, "%d\t%s%s", r.count, r.line, lineEnd)
+		} else {
+			fmt.Fprint(p.w, r.line, lineEnd)
+		}
+	}
+}
+
+func (p *textPrinter) printStats(s setStats) {
+	printStatsText(p.w, s)
+}
+
+func (p *textPrinter) close() error {
+	return nil
+}
+
+// printCount prints out a line for count given which inputs contain the line,
+// prefixed with the number of those inputs and their 1-based positions.
+func printCount(w io.Writer, in []bool, line string) {
+	pos := make([]string, 0, len(in))
+	for i, ok := range in {
+		if ok {
+			pos = append(pos, strconv.Itoa(i+1))
+		}
+	}
+	fmt.Fprintf(w, "%d\t%s\t%s%s", len(pos), strings.Join(pos, ","), line, lineEnd)
+}
+
+// commColumn returns the 0-based comm column of a line given which inputs
+// contain the line.
+func commColumn(in []bool) int {
+	switch {
+	case !in[1]:
+		return 0
+	case !in[0]:
+		return 1
+	}
+	return 2
+}
+
+// printComm prints out a line for comm given which inputs contain the line.
+// Columns are separated by tabs, with no tabs for suppressed columns. Lines
+// of suppressed columns are dropped by the comm command before printing.
+func printComm(w io.Writer, in []bool, line string) {
+	indent := 0
+	for _, shown := range commColumns[:commColumn(in)] {
+		if shown {
+			indent++
+		}
+	}
+	fmt.Fprintf(w, "%s%s%s", strings.Repeat("\t", indent), line, lineEnd)
+}
+
+// jsonRecord is a result in the JSON formats.
+type jsonRecord struct {
+	Value string   `json:"value"`
+	In    []string `json:"in"`
+	Count *int     `json:"count,omitempty"`
+}
+
+// jsonSummary is the summary of the results in the JSON formats.
+type jsonSummary struct {
+	// Count is the number of results.
+	Count int `json:"count"`
+	// Files has the number of results in each input.
+	Files []jsonFileCount `json:"files"`
+}
+
+type jsonFileCount struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// jsonPrinter prints results as a JSON object with a results array followed
+// by a summary, or as newline delimited JSON with a record per line followed
+// by a line with the summary. The first error is kept and returned by close,
+// after which nothing more is printed.
+type jsonPrinter struct {
+	w     io.Writer
+	names []string
+	bag   bool
+	nd    bool
+
+	count  int
+	counts []int
+	stats  *jsonStats
+	err    error
+}
+
+func (p *jsonPrinter) print(r result) {
+	if p.err != nil {
+		return
+	}
+	rec := jsonRecord{Value: r.line, In: []string{}}
+	for i, ok := range r.in {
+		if ok {
+			rec.In = append(rec.In, p.names[i])
+		}
+	}
+	if p.bag {
+		rec.Count = &r.count
+	}
+	b, err := marshal(rec)
+	if err != nil {
+		p.err = err
+		return
+	}
+	for i, ok := range r.in {
+		if ok {
+			p.counts[i]++
+		}
+	}
+	switch {
+	case p.nd:
+	case p.count == 0:
+		p.write("{\"results\":[\n")
+	default:
+		p.write(",\n")
+	}
+	p.count++
+	p.write(string(b))
+	if p.nd {
+		p.write("\n")
+	}
+}
+
+// write writes s unless there was an error.
+func (p *jsonPrinter) write(s string) {
+	if p.err == nil {
+		_, p.err = io.WriteString(p.w, s)
+	}
+}
+
+// printStats prints the statistics as a single object when closed.
+func (p *jsonPrinter) printStats(s setStats) {
+	js := newJSONStats(p.names, s)
+	p.stats = &js
+}
+
+func (p *jsonPrinter) close() error {
+	if p.err != nil {
+		return p.err
+	}
+	if p.stats != nil {
+		b, err := marshal(p.stats)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(p.w, "%s\n", b)
+		return err
+	}
+	sum := jsonSummary{Count: p.count, Files: make([]jsonFileCount, len(p.names))}
+	for i, name := range p.names {
+		sum.Files[i] = jsonFileCount{Name: name, Count: p.counts[i]}
+	}
+	b, err := marshal(sum)
+	if err != nil {
+		return err
+	}
+	switch {
+	case p.nd:
+		_, err = fmt.Fprintf(p.w, "{\"summary\":%s}\n", b)
+	case p.count == 0:
+		_, err = fmt.Fprintf(p.w, "{\"results\":[],\"summary\":%s}\n", b)
+	default:
+		_, err = fmt.Fprintf(p.w, "\n],\"summary\":%s}\n", b)
+	}
+	return err
+}
+
+// marshal returns the JSON encoding of v without escaping HTML characters.
+func marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// csvPrinter prints results as CSV rows with the value, a column for each
+// input that is 1 if the input contains the value or else 0, and the count
+// for -bag.
+type csvPrinter struct {
+	w   *csv.Writer
+	bag bool
+}
+
+func (p *csvPrinter) print(r result) {
+	row := make([]string, 0, len(r.in)+2)
+	row = append(row, r.line)
+	for _, ok := range r.in {
+		if ok {
+			row = append(row, "1")
+		} else {
+			row = append(row, "0")
+		}
+	}
+	if p.bag {
+		row = append(row, strconv.Itoa(r.count))
+	}
+	p.w.Write(row)
+}
+
+func (p *csvPrinter) printStats(s setStats) {
+	for _, row := range s.rows() {
+		p.w.Write(row[:])
+	}
+}
+
+func (p *csvPrinter) close() error {
+	p.w.Flush()
+	return p.w.Error()
+}
@@ -0,0 +1,270 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/cybrcodr/txttools/lset/internal/set"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNewPrinter(t *testing.T) {
+	names := []string{"a.txt", "b.txt"}
+	for _, test := range []struct {
+		format string
+		cmd    string
+		bag    bool
+		want   string
+	}{
+		{format: "text", cmd: "union"},
+		{format: "json", cmd: "union"},
+		{format: "ndjson", cmd: "union"},
+		{format: "csv", cmd: "union", want: "value,a.txt,b.txt\n"},
+		{format: "csv", cmd: "union", bag: true, want: "value,a.txt,b.txt,count\n"},
+		{format: "csv", cmd: "stats", want: "stat,value\n"},
+	} {
+		var buf bytes.Buffer
+		p, err := newPrinter(&buf, test.format, test.cmd, names, test.bag)
+		if err != nil {
+			t.Errorf("newPrinter(%q, %q, %t) error: %v", test.format, test.cmd, test.bag, err)
+			continue
+		}
+		if err := p.close(); err != nil {
+			t.Errorf("newPrinter(%q, %q, %t): close error: %v", test.format, test.cmd, test.bag, err)
+		}
+		// JSON printers always print a summary when closed.
+		if test.format == "json" || test.format == "ndjson" {
+			continue
+		}
+		if got := buf.String(); got != test.want {
+			t.Errorf("newPrinter(%q, %q, %t) printed %q, want %q", test.format, test.cmd, test.bag, got, test.want)
+		}
+	}
+
+	for _, format := range []string{"", "xml", "JSON"} {
+		if _, err := newPrinter(&bytes.Buffer{}, format, "union", names, false); err == nil {
+			t.Errorf("newPrinter(%q) succeeded", format)
+		}
+	}
+}
+
+func TestPrinters(t *testing.T) {
+	names := []string{"a.txt", "b.txt"}
+	results := []result{
+		{line: "x", in: []bool{true, false}, count: 2},
+		{line: `say "<hi>", bye`, in: []bool{true, true}, count: 1},
+	}
+	stats := newSetStats(3, 2, 1)
+	for _, test := range []struct {
+		desc    string
+		format  string
+		bag     bool
+		results []result
+		stats   *setStats
+		want    string
+	}{
+		{
+			desc:   "empty",
+			format: "json",
+			want:   `{"results":[],"summary":{"count":0,"files":[{"name":"a.txt","count":0},{"name":"b.txt","count":0}]}}` + "\n",
+		},
+		{
+			desc:    "results",
+			format:  "json",
+			results: results,
+			want: "{\"results\":[\n" +
+				`{"value":"x","in":["a.txt"]},` + "\n" +
+				`{"value":"say \"<hi>\", bye","in":["a.txt","b.txt"]}` + "\n" +
+				`],"summary":{"count":2,"files":[{"name":"a.txt","count":2},{"name":"b.txt","count":1}]}}` + "\n",
+		},
+		{
+			desc:    "bag",
+			format:  "json",
+			bag:     true,
+			results: results[:1],
+			want: "{\"results\":[\n" +
+				`{"value":"x","in":["a.txt"],"count":2}` + "\n" +
+				`],"summary":{"count":1,"files":[{"name":"a.txt","count":1},{"name":"b.txt","count":0}]}}` + "\n",
+		},
+		{
+			desc:   "stats",
+			format: "json",
+			stats:  &stats,
+			want:   `{"files":[{"name":"a.txt","count":3,"only":2},{"name":"b.txt","count":2,"only":1}],"cross":1,"union":4,"jaccard":0.25,"overlap":0.5}` + "\n",
+		},
+		{
+			desc:   "empty",
+			format: "ndjson",
+			want:   `{"summary":{"count":0,"files":[{"name":"a.txt","count":0},{"name":"b.txt","count":0}]}}` + "\n",
+		},
+		{
+			desc:    "results",
+			format:  "ndjson",
+			results: results,
+			want: `{"value":"x","in":["a.txt"]}` + "\n" +
+				`{"value":"say \"<hi>\", bye","in":["a.txt","b.txt"]}` + "\n" +
+				`{"summary":{"count":2,"files":[{"name":"a.txt","count":2},{"name":"b.txt","count":1}]}}` + "\n",
+		},
+		{
+			desc:    "bag",
+			format:  "ndjson",
+			bag:     true,
+			results: results,
+			want: `{"value":"x","in":["a.txt"],"count":2}` + "\n" +
+				`{"value":"say \"<hi>\", bye","in":["a.txt","b.txt"],"count":1}` + "\n" +
+				`{"summary":{"count":2,"files":[{"name":"a.txt","count":2},{"name":"b.txt","count":1}]}}` + "\n",
+		},
+		{
+			desc:   "stats",
+			format: "ndjson",
+			stats:  &stats,
+			want:   `{"files":[{"name":"a.txt","count":3,"only":2},{"name":"b.txt","count":2,"only":1}],"cross":1,"union":4,"jaccard":0.25,"overlap":0.5}` + "\n",
+		},
+		{
+			desc:   "empty",
+			format: "csv",
+			want:   "value,a.txt,b.txt\n",
+		},
+		{
+			desc:    "results",
+			format:  "csv",
+			results: results,
+			want:    "value,a.txt,b.txt\nx,1,0\n\"say \"\"<hi>\"\", bye\",1,1\n",
+		},
+		{
+			desc:    "bag",
+			format:  "csv",
+			bag:     true,
+			results: results,
+			want:    "value,a.txt,b.txt,count\nx,1,0,2\n\"say \"\"<hi>\"\", bye\",1,1,1\n",
+		},
+		{
+			desc:   "stats",
+			format: "csv",
+			stats:  &stats,
+			want:   "stat,value\nsize1,3\nsize2,2\ncross,1\nunion,4\nonly1,2\nonly2,1\njaccard,0.2500\noverlap,0.5000\n",
+		},
+	} {
+		cmd := "union"
+		if test.stats != nil {
+			cmd = "stats"
+		}
+		var buf bytes.Buffer
+		p, err := newPrinter(&buf, test.format, cmd, names, test.bag)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, r := range test.results {
+			p.print(r)
+		}
+		if test.stats != nil {
+			p.printStats(*test.stats)
+		}
+		if err := p.close(); err != nil {
+			t.Errorf("%s %s: close error: %v", test.format, test.desc, err)
+		}
+		if diff := cmp.Diff(test.want, buf.String()); diff != "" {
+			t.Errorf("%s %s: -want +got\n%s", test.format, test.desc, diff)
+		}
+	}
+}
+
+// TestCommFormats checks that comm suppresses columns in all output formats.
+func TestCommFormats(t *testing.T) {
+	defer func(c [3]bool) { commColumns = c }(commColumns)
+	commColumns = [3]bool{true, true, false}
+
+	names := []string{"a.txt", "b.txt"}
+	inputs := []*input{
+		{keys: set.NewStrings("a", "c")},
+		{keys: set.NewStrings("b", "c")},
+	}
+	for _, test := range []struct {
+		format string
+		want   string
+	}{
+		{format: "text", want: "a\n\tb\n"},
+		{
+			format: "ndjson",
+			want: `{"value":"a","in":["a.txt"]}` + "\n" +
+				`{"value":"b","in":["b.txt"]}` + "\n" +
+				`{"summary":{"count":2,"files":[{"name":"a.txt","count":1},{"name":"b.txt","count":1}]}}` + "\n",
+		},
+		{format: "csv", want: "value,a.txt,b.txt\na,1,0\nb,0,1\n"},
+	} {
+		var buf bytes.Buffer
+		p, err := newPrinter(&buf, test.format, "comm", names, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		comm(p, inputs)
+		if err := p.close(); err != nil {
+			t.Errorf("%s: close error: %v", test.format, err)
+		}
+		if diff := cmp.Diff(test.want, buf.String()); diff != "" {
+			t.Errorf("%s: -want +got\n%s", test.format, diff)
+		}
+	}
+}
+
+// errWriter fails all writes after n bytes.
+type errWriter struct {
+	n int
+}
+
+func (w *errWriter) Write(b []byte) (int, error) {
+	if len(b) > w.n {
+		n := w.n
+		w.n = 0
+		return n, errors.New("write failed")
+	}
+	w.n -= len(b)
+	return len(b), nil
+}
+
+func TestJSONPrinterErrors(t *testing.T) {
+	names := []string{"a.txt", "b.txt"}
+	for _, format := range []string{"json", "ndjson"} {
+		// Statistics that cannot be encoded.
+		var buf bytes.Buffer
+		p, err := newPrinter(&buf, format, "stats", names, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p.printStats(setStats{jaccard: math.NaN()})
+		if err := p.close(); err == nil {
+			t.Errorf("%s: close with NaN statistics succeeded", format)
+		}
+		if buf.Len() != 0 {
+			t.Errorf("%s: printed %q with NaN statistics", format, buf.String())
+		}
+
+		// A writer that fails on the second record.
+		w := &errWriter{n: 40}
+		if p, err = newPrinter(w, format, "union", names, false); err != nil {
+			t.Fatal(err)
+		}
+		for _, line := range []string{"x", "y", "z"} {
+			p.print(result{line: line, in: []bool{true, false}})
+		}
+		if err := p.close(); err == nil || err.Error() != "write failed" {
+			t.Errorf("%s: close error %v, want write failed", format, err)
+		}
+	}
+}
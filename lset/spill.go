@@ -0,0 +1,98 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"github.com/cybrcodr/txttools/internal/infile"
+	"github.com/cybrcodr/txttools/internal/lines"
+	"github.com/cybrcodr/txttools/lset/internal/extsort"
+)
+
+// spillFile sorts the line items of the named file by key in chunks of up to
+// limit bytes written out to temporary files in dir, and returns a stream
+// over the sorted keys.
+func spillFile(filename string, opts readOptions, dir string, limit int) (stream, error) {
+	f, err := infile.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	sorter := extsort.New(dir, limit)
+	r := lines.NewRecordReader(f, filename, opts.maxLine, opts.sep)
+	for r.Scan() {
+		// Line items that are their own keys are only stored once.
+		line := r.Text()
+		key, value := line, ""
+		if opts.key != nil {
+			key, value = opts.key(line), line
+		}
+		if err := sorter.Add(key, value); err != nil {
+			sorter.Close()
+			return nil, err
+		}
+	}
+	if err := r.Err(); err != nil {
+		sorter.Close()
+		return nil, err
+	}
+	it, err := sorter.Sort()
+	if err != nil {
+		return nil, err
+	}
+	return &spillStream{it: it, keyed: opts.key != nil}, nil
+}
+
+// spillStream is a stream over line items sorted by extsort. Line items with
+// the same key are sorted in the order they were read, the first one of which
+// is kept.
+type spillStream struct {
+	it      *extsort.Iterator
+	keyed   bool
+	started bool
+	rec     extsort.Record
+}
+
+func (s *spillStream) Next() bool {
+	for s.it.Next() {
+		rec := s.it.Record()
+		if s.started && rec.Key == s.rec.Key {
+			continue
+		}
+		s.started = true
+		s.rec = rec
+		return true
+	}
+	return false
+}
+
+func (s *spillStream) Key() string {
+	return s.rec.Key
+}
+
+func (s *spillStream) Line() string {
+	if s.keyed {
+		return s.rec.Value
+	}
+	return s.rec.Key
+}
+
+func (s *spillStream) Err() error {
+	return s.it.Err()
+}
+
+func (s *spillStream) Close() error {
+	return s.it.Close()
+}
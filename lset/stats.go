@@ -0,0 +1,118 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// setStats are summary statistics of two sets.
+type setStats struct {
+	// sizes are the number of distinct values of each set.
+	sizes [2]int
+	cross int
+	union int
+	// only are the number of values that are only in each set.
+	only [2]int
+	// jaccard is the size of the intersection over the size of the union.
+	jaccard float64
+	// overlap is the size of the intersection over the size of the smaller
+	// set.
+	overlap float64
+}
+
+// newSetStats returns the statistics of two sets given their sizes and the
+// size of their intersection. The coefficients of two empty sets are 1, and
+// the overlap coefficient is 1 if either set is empty since it is then a
+// subset of the other.
+func newSetStats(size1, size2, cross int) setStats {
+	s := setStats{
+		sizes:   [2]int{size1, size2},
+		cross:   cross,
+		union:   size1 + size2 - cross,
+		only:    [2]int{size1 - cross, size2 - cross},
+		jaccard: 1,
+		overlap: 1,
+	}
+	if s.union > 0 {
+		s.jaccard = float64(cross) / float64(s.union)
+	}
+	if smaller := min(size1, size2); smaller > 0 {
+		s.overlap = float64(cross) / float64(smaller)
+	}
+	return s
+}
+
+// stats prints out the statistics of inputs[0] and inputs[1].
+func stats(p printer, inputs []*input) {
+	a, b := inputs[0].keys, inputs[1].keys
+	p.printStats(newSetStats(len(a), len(b), len(a.Intersect(b))))
+}
+
+// rows returns the names and values of the statistics in output order.
+func (s setStats) rows() [][2]string {
+	itoa := strconv.Itoa
+	ftoa := func(f float64) string {
+		return strconv.FormatFloat(f, 'f', 4, 64)
+	}
+	return [][2]string{
+		{"size1", itoa(s.sizes[0])},
+		{"size2", itoa(s.sizes[1])},
+		{"cross", itoa(s.cross)},
+		{"union", itoa(s.union)},
+		{"only1", itoa(s.only[0])},
+		{"only2", itoa(s.only[1])},
+		{"jaccard", ftoa(s.jaccard)},
+		{"overlap", ftoa(s.overlap)},
+	}
+}
+
+// printStatsText prints out the statistics as a line per statistic with its
+// name and value separated by a tab, ending with lineEnd.
+func printStatsText(w io.Writer, s setStats) {
+	for _, row := range s.rows() {
+		fmt.Fprintf(w, "%s\t%s%s", row[0], row[1], lineEnd)
+	}
+}
+
+// jsonStats is the statistics in the JSON formats.
+type jsonStats struct {
+	Files   []jsonFileStats `json:"files"`
+	Cross   int             `json:"cross"`
+	Union   int             `json:"union"`
+	Jaccard float64         `json:"jaccard"`
+	Overlap float64         `json:"overlap"`
+}
+
+type jsonFileStats struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+	Only  int    `json:"only"`
+}
+
+func newJSONStats(names []string, s setStats) jsonStats {
+	js := jsonStats{
+		Cross:   s.cross,
+		Union:   s.union,
+		Jaccard: s.jaccard,
+		Overlap: s.overlap,
+	}
+	for i, name := range names {
+		js.Files = append(js.Files, jsonFileStats{Name: name, Count: s.sizes[i], Only: s.only[i]})
+	}
+	return js
+}
@@ -0,0 +1,192 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package set contains a generic set data structure.
+package set
+
+import (
+	"iter"
+	"slices"
+)
+
+// Set is a set of values of type T.
+type Set[T comparable] map[T]struct{}
+
+// New constructs a Set with the given values.
+func New[T comparable](values ...T) Set[T] {
+	s := make(Set[T], len(values))
+	s.Add(values...)
+	return s
+}
+
+// Add adds given value(s).
+func (s Set[T]) Add(values ...T) {
+	for _, v := range values {
+		s[v] = struct{}{}
+	}
+}
+
+// AddSet adds values from another Set.
+func (s Set[T]) AddSet(o Set[T]) {
+	for v := range o {
+		s[v] = struct{}{}
+	}
+}
+
+// Remove removes given value(s).
+func (s Set[T]) Remove(values ...T) {
+	for _, v := range values {
+		delete(s, v)
+	}
+}
+
+// RemoveSet removes values from another Set.
+func (s Set[T]) RemoveSet(o Set[T]) {
+	for v := range o {
+		delete(s, v)
+	}
+}
+
+// Len returns the number of values in the set.
+func (s Set[T]) Len() int {
+	return len(s)
+}
+
+// Equal returns true if s and o contain the same values.
+func (s Set[T]) Equal(o Set[T]) bool {
+	if len(s) != len(o) {
+		return false
+	}
+	for v := range s {
+		if !o.Contains(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsSubset returns true if all values of s are in o.
+func (s Set[T]) IsSubset(o Set[T]) bool {
+	if len(s) > len(o) {
+		return false
+	}
+	for v := range s {
+		if !o.Contains(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsSuperset returns true if all values of o are in s.
+func (s Set[T]) IsSuperset(o Set[T]) bool {
+	return o.IsSubset(s)
+}
+
+// IsDisjoint returns true if s and o have no values in common.
+func (s Set[T]) IsDisjoint(o Set[T]) bool {
+	if len(s) > len(o) {
+		s, o = o, s
+	}
+	for v := range s {
+		if o.Contains(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// Contains returns true if value is in set, else false.
+func (s Set[T]) Contains(v T) bool {
+	_, ok := s[v]
+	return ok
+}
+
+// All returns an iterator over the values of the set in no particular order.
+func (s Set[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for v := range s {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// Slice returns values within set in no particular order.
+func (s Set[T]) Slice() []T {
+	ret := make([]T, 0, len(s))
+	for v := range s {
+		ret = append(ret, v)
+	}
+	return ret
+}
+
+// SortedSlice returns values within set sorted by cmp, which returns a
+// negative number if a < b, a positive number if a > b and zero if a == b,
+// such as cmp.Compare.
+func (s Set[T]) SortedSlice(cmp func(a, b T) int) []T {
+	ret := s.Slice()
+	slices.SortFunc(ret, cmp)
+	return ret
+}
+
+// Intersect returns the intersection of s and o.
+func (s Set[T]) Intersect(o Set[T]) Set[T] {
+	ret := Set[T]{}
+	for v := range s {
+		if o.Contains(v) {
+			ret.Add(v)
+		}
+	}
+	return ret
+}
+
+// Union returns the union of s and o.
+func (s Set[T]) Union(o Set[T]) Set[T] {
+	ret := make(Set[T], len(s))
+	ret.AddSet(s)
+	ret.AddSet(o)
+	return ret
+}
+
+// SymmetricDifference returns values that are in either s or o but not in
+// both.
+func (s Set[T]) SymmetricDifference(o Set[T]) Set[T] {
+	ret := s.Minus(o)
+	for v := range o {
+		if !s.Contains(v) {
+			ret.Add(v)
+		}
+	}
+	return ret
+}
+
+// Diff returns the difference between the current and other sets.
+// The first returned value are values in the other set not in the current.
+// The second returned value are values in the current set not in the other.
+func (s Set[T]) Diff(other Set[T]) (Set[T], Set[T]) {
+	return other.Minus(s), s.Minus(other)
+}
+
+// Minus returns values in current set that are not in other set.
+func (s Set[T]) Minus(other Set[T]) Set[T] {
+	ret := Set[T]{}
+	for v := range s {
+		if !other.Contains(v) {
+			ret.Add(v)
+		}
+	}
+	return ret
+}
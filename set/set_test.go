@@ -0,0 +1,208 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package set
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func compareInts(a, b int) int {
+	return a - b
+}
+
+func TestNew(t *testing.T) {
+	for i, conf := range []struct {
+		input []int
+		want  Set[int]
+	}{
+		{
+			input: nil,
+			want:  Set[int]{},
+		},
+		{
+			input: []int{1},
+			want:  Set[int]{1: {}},
+		},
+		{
+			input: []int{2, 1, 2},
+			want:  Set[int]{1: {}, 2: {}},
+		},
+	} {
+		got := New(conf.input...)
+		if diff := cmp.Diff(got, conf.want); diff != "" {
+			t.Errorf("testcase %d\n%s", i, diff)
+		}
+		if got.Len() != len(conf.want) {
+			t.Errorf("testcase %d: Len() = %d, want %d", i, got.Len(), len(conf.want))
+		}
+	}
+}
+
+func TestAddRemove(t *testing.T) {
+	s := New(1, 2)
+	s.Add(3)
+	s.AddSet(New(4, 5))
+	s.Remove(1)
+	s.RemoveSet(New(5, 6))
+	if diff := cmp.Diff(s, New(2, 3, 4)); diff != "" {
+		t.Error(diff)
+	}
+	for _, v := range []int{2, 3, 4} {
+		if !s.Contains(v) {
+			t.Errorf("Contains(%d) = false", v)
+		}
+	}
+	for _, v := range []int{1, 5, 6} {
+		if s.Contains(v) {
+			t.Errorf("Contains(%d) = true", v)
+		}
+	}
+}
+
+func TestEqual(t *testing.T) {
+	for i, conf := range []struct {
+		a, b Set[string]
+		want bool
+	}{
+		{a: New[string](), b: New[string](), want: true},
+		{a: New("a", "b"), b: New("b", "a"), want: true},
+		{a: New("a"), b: New("a", "b"), want: false},
+		{a: New("a", "c"), b: New("a", "b"), want: false},
+	} {
+		if got := conf.a.Equal(conf.b); got != conf.want {
+			t.Errorf("testcase %d: got %t, want %t", i, got, conf.want)
+		}
+	}
+}
+
+func TestPredicates(t *testing.T) {
+	for i, conf := range []struct {
+		a, b                         Set[int]
+		subset, superset, isDisjoint bool
+	}{
+		{a: New[int](), b: New[int](), subset: true, superset: true, isDisjoint: true},
+		{a: New[int](), b: New(1), subset: true, superset: false, isDisjoint: true},
+		{a: New(1, 2), b: New(1, 2, 3), subset: true, superset: false, isDisjoint: false},
+		{a: New(1, 2, 3), b: New(2, 3), subset: false, superset: true, isDisjoint: false},
+		{a: New(1, 2), b: New(2, 1), subset: true, superset: true, isDisjoint: false},
+		{a: New(1, 4), b: New(1, 2, 3), subset: false, superset: false, isDisjoint: false},
+		{a: New(1, 2), b: New(3, 4, 5), subset: false, superset: false, isDisjoint: true},
+	} {
+		if got := conf.a.IsSubset(conf.b); got != conf.subset {
+			t.Errorf("testcase %d: IsSubset() = %t, want %t", i, got, conf.subset)
+		}
+		if got := conf.a.IsSuperset(conf.b); got != conf.superset {
+			t.Errorf("testcase %d: IsSuperset() = %t, want %t", i, got, conf.superset)
+		}
+		if got := conf.a.IsDisjoint(conf.b); got != conf.isDisjoint {
+			t.Errorf("testcase %d: IsDisjoint() = %t, want %t", i, got, conf.isDisjoint)
+		}
+		if got := conf.b.IsDisjoint(conf.a); got != conf.isDisjoint {
+			t.Errorf("testcase %d: reversed IsDisjoint() = %t, want %t", i, got, conf.isDisjoint)
+		}
+
+		a, b := NewSharded(conf.a.Slice()...), NewSharded(conf.b.Slice()...)
+		if a.IsSubset(b) != conf.subset || a.IsSuperset(b) != conf.superset || a.IsDisjoint(b) != conf.isDisjoint {
+			t.Errorf("testcase %d: Sharded predicates differ", i)
+		}
+	}
+}
+
+func TestAll(t *testing.T) {
+	s := New(3, 1, 2)
+	var got []int
+	for v := range s.All() {
+		got = append(got, v)
+	}
+	slices.Sort(got)
+	if diff := cmp.Diff(got, []int{1, 2, 3}); diff != "" {
+		t.Error(diff)
+	}
+
+	// Stopping early must not visit more values.
+	n := 0
+	for range s.All() {
+		n++
+		break
+	}
+	if n != 1 {
+		t.Errorf("visited %d values after break", n)
+	}
+}
+
+func TestSortedSlice(t *testing.T) {
+	for i, conf := range []struct {
+		input []string
+		cmp   func(a, b string) int
+		want  []string
+	}{
+		{
+			input: nil,
+			cmp:   strings.Compare,
+			want:  []string{},
+		},
+		{
+			input: []string{"z", "a", "j"},
+			cmp:   strings.Compare,
+			want:  []string{"a", "j", "z"},
+		},
+		{
+			input: []string{"z", "a", "j"},
+			cmp: func(a, b string) int {
+				return strings.Compare(b, a)
+			},
+			want: []string{"z", "j", "a"},
+		},
+	} {
+		got := New(conf.input...).SortedSlice(conf.cmp)
+		if diff := cmp.Diff(got, conf.want); diff != "" {
+			t.Errorf("testcase %d\n%s", i, diff)
+		}
+	}
+}
+
+func TestOperations(t *testing.T) {
+	a, b := New(1, 2, 3), New(3, 4)
+	for _, conf := range []struct {
+		desc string
+		got  Set[int]
+		want []int
+	}{
+		{desc: "Intersect", got: a.Intersect(b), want: []int{3}},
+		{desc: "Union", got: a.Union(b), want: []int{1, 2, 3, 4}},
+		{desc: "SymmetricDifference", got: a.SymmetricDifference(b), want: []int{1, 2, 4}},
+		{desc: "Minus", got: a.Minus(b), want: []int{1, 2}},
+	} {
+		if diff := cmp.Diff(conf.got.SortedSlice(compareInts), conf.want); diff != "" {
+			t.Errorf("%s\n%s", conf.desc, diff)
+		}
+	}
+
+	adds, subs := a.Diff(b)
+	if diff := cmp.Diff(adds.SortedSlice(compareInts), []int{4}); diff != "" {
+		t.Errorf("Diff adds\n%s", diff)
+	}
+	if diff := cmp.Diff(subs.SortedSlice(compareInts), []int{1, 2}); diff != "" {
+		t.Errorf("Diff subs\n%s", diff)
+	}
+	// Operations must not modify their operands.
+	if !a.Equal(New(1, 2, 3)) || !b.Equal(New(3, 4)) {
+		t.Errorf("operands modified to %v and %v", a, b)
+	}
+}
@@ -0,0 +1,258 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package set
+
+import (
+	"hash/maphash"
+	"iter"
+	"slices"
+	"sync"
+)
+
+// numShards is the number of shards of a Sharded set.
+const numShards = 64
+
+// Sharded is a set of values of type T that is safe for concurrent use. Values
+// are spread over shards that are each locked separately, so that goroutines
+// adding different values rarely wait on each other.
+//
+// Methods that read the whole set see each shard at a different time, hence
+// their results reflect some interleaving of concurrent changes.
+type Sharded[T comparable] struct {
+	seed   maphash.Seed
+	shards [numShards]shard[T]
+}
+
+type shard[T comparable] struct {
+	mu  sync.RWMutex
+	set Set[T]
+}
+
+// NewSharded constructs a Sharded set with the given values.
+func NewSharded[T comparable](values ...T) *Sharded[T] {
+	s := &Sharded[T]{seed: maphash.MakeSeed()}
+	for i := range s.shards {
+		s.shards[i].set = Set[T]{}
+	}
+	s.Add(values...)
+	return s
+}
+
+func (s *Sharded[T]) shard(v T) *shard[T] {
+	return &s.shards[maphash.Comparable(s.seed, v)%numShards]
+}
+
+// Add adds given value(s).
+func (s *Sharded[T]) Add(values ...T) {
+	for _, v := range values {
+		sh := s.shard(v)
+		sh.mu.Lock()
+		sh.set[v] = struct{}{}
+		sh.mu.Unlock()
+	}
+}
+
+// AddSet adds values from another Sharded set.
+func (s *Sharded[T]) AddSet(o *Sharded[T]) {
+	for v := range o.All() {
+		s.Add(v)
+	}
+}
+
+// Remove removes given value(s).
+func (s *Sharded[T]) Remove(values ...T) {
+	for _, v := range values {
+		sh := s.shard(v)
+		sh.mu.Lock()
+		delete(sh.set, v)
+		sh.mu.Unlock()
+	}
+}
+
+// RemoveSet removes values from another Sharded set.
+func (s *Sharded[T]) RemoveSet(o *Sharded[T]) {
+	for v := range o.All() {
+		s.Remove(v)
+	}
+}
+
+// Len returns the number of values in the set.
+func (s *Sharded[T]) Len() int {
+	n := 0
+	for i := range s.shards {
+		sh := &s.shards[i]
+		sh.mu.RLock()
+		n += len(sh.set)
+		sh.mu.RUnlock()
+	}
+	return n
+}
+
+// Equal returns true if s and o contain the same values.
+func (s *Sharded[T]) Equal(o *Sharded[T]) bool {
+	return s.Set().Equal(o.Set())
+}
+
+// IsSubset returns true if all values of s are in o.
+func (s *Sharded[T]) IsSubset(o *Sharded[T]) bool {
+	for v := range s.All() {
+		if !o.Contains(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsSuperset returns true if all values of o are in s.
+func (s *Sharded[T]) IsSuperset(o *Sharded[T]) bool {
+	return o.IsSubset(s)
+}
+
+// IsDisjoint returns true if s and o have no values in common.
+func (s *Sharded[T]) IsDisjoint(o *Sharded[T]) bool {
+	for v := range s.All() {
+		if o.Contains(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// Contains returns true if value is in set, else false.
+func (s *Sharded[T]) Contains(v T) bool {
+	sh := s.shard(v)
+	sh.mu.RLock()
+	_, ok := sh.set[v]
+	sh.mu.RUnlock()
+	return ok
+}
+
+// All returns an iterator over the values of the set in no particular order.
+// The values of each shard are copied before they are yielded, so the set can
+// be changed while iterating.
+func (s *Sharded[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for i := range s.shards {
+			sh := &s.shards[i]
+			sh.mu.RLock()
+			values := sh.set.Slice()
+			sh.mu.RUnlock()
+			for _, v := range values {
+				if !yield(v) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Set returns a copy of the values as a Set.
+func (s *Sharded[T]) Set() Set[T] {
+	ret := make(Set[T], s.Len())
+	for v := range s.All() {
+		ret[v] = struct{}{}
+	}
+	return ret
+}
+
+// Take moves the values to a Set and leaves s empty. Unlike Set, the values
+// are not held twice: each shard is released once its values are moved, and
+// the largest shard becomes the returned Set.
+func (s *Sharded[T]) Take() Set[T] {
+	largest := 0
+	for i := range s.shards {
+		sh := &s.shards[i]
+		sh.mu.RLock()
+		if len(sh.set) > len(s.shards[largest].set) {
+			largest = i
+		}
+		sh.mu.RUnlock()
+	}
+	sh := &s.shards[largest]
+	sh.mu.Lock()
+	ret := sh.set
+	sh.set = Set[T]{}
+	sh.mu.Unlock()
+	for i := range s.shards {
+		sh := &s.shards[i]
+		sh.mu.Lock()
+		values := sh.set
+		sh.set = Set[T]{}
+		sh.mu.Unlock()
+		ret.AddSet(values)
+	}
+	return ret
+}
+
+// Slice returns values within set in no particular order.
+func (s *Sharded[T]) Slice() []T {
+	return slices.Collect(s.All())
+}
+
+// SortedSlice returns values within set sorted by cmp like Set.SortedSlice.
+func (s *Sharded[T]) SortedSlice(cmp func(a, b T) int) []T {
+	ret := s.Slice()
+	slices.SortFunc(ret, cmp)
+	return ret
+}
+
+// Intersect returns the intersection of s and o.
+func (s *Sharded[T]) Intersect(o *Sharded[T]) *Sharded[T] {
+	ret := NewSharded[T]()
+	for v := range s.All() {
+		if o.Contains(v) {
+			ret.Add(v)
+		}
+	}
+	return ret
+}
+
+// Union returns the union of s and o.
+func (s *Sharded[T]) Union(o *Sharded[T]) *Sharded[T] {
+	ret := NewSharded[T]()
+	ret.AddSet(s)
+	ret.AddSet(o)
+	return ret
+}
+
+// SymmetricDifference returns values that are in either s or o but not in
+// both.
+func (s *Sharded[T]) SymmetricDifference(o *Sharded[T]) *Sharded[T] {
+	ret := s.Minus(o)
+	for v := range o.All() {
+		if !s.Contains(v) {
+			ret.Add(v)
+		}
+	}
+	return ret
+}
+
+// Diff returns the difference between the current and other sets.
+// The first returned value are values in the other set not in the current.
+// The second returned value are values in the current set not in the other.
+func (s *Sharded[T]) Diff(other *Sharded[T]) (*Sharded[T], *Sharded[T]) {
+	return other.Minus(s), s.Minus(other)
+}
+
+// Minus returns values in current set that are not in other set.
+func (s *Sharded[T]) Minus(other *Sharded[T]) *Sharded[T] {
+	ret := NewSharded[T]()
+	for v := range s.All() {
+		if !other.Contains(v) {
+			ret.Add(v)
+		}
+	}
+	return ret
+}
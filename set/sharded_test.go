@@ -0,0 +1,144 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package set
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestShardedOperations(t *testing.T) {
+	a, b := NewSharded(1, 2, 3), NewSharded(3, 4)
+	for _, conf := range []struct {
+		desc string
+		got  *Sharded[int]
+		want []int
+	}{
+		{desc: "Intersect", got: a.Intersect(b), want: []int{3}},
+		{desc: "Union", got: a.Union(b), want: []int{1, 2, 3, 4}},
+		{desc: "SymmetricDifference", got: a.SymmetricDifference(b), want: []int{1, 2, 4}},
+		{desc: "Minus", got: a.Minus(b), want: []int{1, 2}},
+	} {
+		if diff := cmp.Diff(conf.got.SortedSlice(compareInts), conf.want); diff != "" {
+			t.Errorf("%s\n%s", conf.desc, diff)
+		}
+	}
+
+	adds, subs := a.Diff(b)
+	if diff := cmp.Diff(adds.SortedSlice(compareInts), []int{4}); diff != "" {
+		t.Errorf("Diff adds\n%s", diff)
+	}
+	if diff := cmp.Diff(subs.SortedSlice(compareInts), []int{1, 2}); diff != "" {
+		t.Errorf("Diff subs\n%s", diff)
+	}
+	if !a.Equal(NewSharded(3, 2, 1)) || a.Equal(b) {
+		t.Error("Equal is wrong")
+	}
+
+	a.AddSet(b)
+	a.Remove(1)
+	a.RemoveSet(NewSharded(4))
+	if diff := cmp.Diff(a.Set(), New(2, 3)); diff != "" {
+		t.Errorf("after AddSet and removes\n%s", diff)
+	}
+	if a.Len() != 2 || !a.Contains(2) || a.Contains(1) {
+		t.Errorf("Len() = %d, Contains(2) = %t, Contains(1) = %t", a.Len(), a.Contains(2), a.Contains(1))
+	}
+}
+
+func TestShardedTake(t *testing.T) {
+	var values []int
+	for i := 0; i < 1000; i++ {
+		values = append(values, i)
+	}
+	for _, s := range []*Sharded[int]{NewSharded[int](), NewSharded(7), NewSharded(values...)} {
+		want := s.Set()
+		got := s.Take()
+		if diff := cmp.Diff(got, want); diff != "" {
+			t.Errorf("Take()\n%s", diff)
+		}
+		if s.Len() != 0 {
+			t.Errorf("Len() = %d after Take, want 0", s.Len())
+		}
+		// The set can still be used and does not change the taken values.
+		s.Add(-1)
+		if got.Contains(-1) || !s.Contains(-1) {
+			t.Errorf("Add after Take: taken Contains(-1) = %t, Contains(-1) = %t", got.Contains(-1), s.Contains(-1))
+		}
+	}
+}
+
+// The following tests are meant to be run with the race detector, as in
+// go test -race.
+
+func TestShardedConcurrentAdd(t *testing.T) {
+	const goroutines, perGoroutine = 8, 1000
+	s := NewSharded[int]()
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < perGoroutine; i++ {
+				// Half of the values are added by 2 goroutines.
+				s.Add(g/2*perGoroutine + i)
+				s.Contains(i)
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	want := New[int]()
+	for i := 0; i < goroutines/2*perGoroutine; i++ {
+		want.Add(i)
+	}
+	if diff := cmp.Diff(s.Set(), want); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestShardedConcurrentReadWrite(t *testing.T) {
+	a, b := NewSharded[int](), NewSharded[int]()
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 500; i++ {
+				switch g {
+				case 0:
+					a.Add(i)
+					b.Remove(i)
+				case 1:
+					b.Add(i)
+					a.Remove(i - 1)
+				case 2:
+					// Operations in both directions must not deadlock.
+					a.Intersect(b)
+					b.Union(a)
+				default:
+					for v := range a.All() {
+						b.Contains(v)
+						a.Add(v)
+					}
+					a.Len()
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+}